@@ -9,11 +9,16 @@ import (
 
 var ErrCacheNotFound = errors.New("cache not found")
 
+// ErrCacheClosed is returned by the operations of closed caches.
+var ErrCacheClosed = errors.New("cache closed")
+
 // Cache is a generic cache wrapper.
 type Cache[T any] struct {
 	cache.Cache
-	encoder func(T) ([]byte, error)
-	decoder func([]byte) (T, error)
+	encoder  func(T) ([]byte, error)
+	decoder  func([]byte) (T, error)
+	version  uint64
+	upgrades map[uint64]func(data []byte) (T, error)
 }
 
 func New[T any](cache cache.Cache, opts ...Option[T]) (*Cache[T], error) {
@@ -41,7 +46,9 @@ func (c *Cache[T]) Get(key string) (T, error) {
 	if err != nil {
 		return *new(T), err
 	}
-	if v, err := c.decoder([]byte(v)); err != nil {
+	if v, err := c.decode([]byte(v)); errors.Is(err, errVersionMismatch) {
+		return *new(T), ErrCacheNotFound
+	} else if err != nil {
 		return *new(T), err
 	} else {
 		return v, nil
@@ -49,14 +56,32 @@ func (c *Cache[T]) Get(key string) (T, error) {
 }
 
 func (c *Cache[T]) Set(key string, value T, expire time.Duration) error {
-	bs, err := c.encoder(value)
+	bs, err := c.encode(value)
 	if err != nil {
 		return err
 	}
 	return c.Cache.Set(key, string(bs), expire)
 }
 
+// Load gets the value, or loads and stores it if it does not exist or is of another version.
+// Values of another version are deleted and loaded again through the store.
 func (c *Cache[T]) Load(key string, loader func() (T, error), expire time.Duration) (T, error) {
+	value, err := c.load(key, loader, expire)
+	if !errors.Is(err, errVersionMismatch) {
+		return value, err
+	}
+	if err := c.Cache.Delete(key); err != nil {
+		return *new(T), err
+	}
+	value, err = c.load(key, loader, expire)
+	if errors.Is(err, errVersionMismatch) {
+		// replaced by another version in the meantime
+		return loader()
+	}
+	return value, err
+}
+
+func (c *Cache[T]) load(key string, loader func() (T, error), expire time.Duration) (T, error) {
 	var loaded bool
 	var value T
 	v, err := c.Cache.Load(key, func() (string, error) {
@@ -66,7 +91,7 @@ func (c *Cache[T]) Load(key string, loader func() (T, error), expire time.Durati
 		}
 		loaded = true
 		value = v
-		bs, err := c.encoder(v)
+		bs, err := c.encode(v)
 		if err != nil {
 			return "", err
 		}
@@ -78,15 +103,20 @@ func (c *Cache[T]) Load(key string, loader func() (T, error), expire time.Durati
 	if loaded {
 		return value, nil
 	}
-	return c.decoder([]byte(v))
+	return c.decode([]byte(v))
 }
 
 func (c *Cache[T]) Delete(key string) error {
 	return c.Cache.Delete(key)
 }
 
+// Has checks if the value exists, values of another version which cannot be upgraded do not exist.
 func (c *Cache[T]) Has(key string) bool {
-	return c.Cache.Has(key)
+	if c.version == 0 {
+		return c.Cache.Has(key)
+	}
+	_, err := c.Get(key)
+	return err == nil
 }
 
 func (c *Cache[T]) Clear() error {
@@ -0,0 +1,208 @@
+package cache
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/gopi-frame/exception"
+)
+
+// codecPrefix starts the envelopes of values encoded through [WithCodec], it is followed by
+// the length of the codec name, the codec name and the encoded value.
+var codecPrefix = []byte(envelopePrefix(envelopeCodec))
+
+// Codec encodes values of type T.
+type Codec[T any] interface {
+	// Name identifies the encoding, it is stored along with the values.
+	Name() string
+	Encode(value T) ([]byte, error)
+	Decode(data []byte) (T, error)
+}
+
+// WithCodec sets the codec.
+// The codec name is stored before every value, decoding a value written with another codec returns
+// a [CodecMismatchException]. Values without the name, such as those written before the codec was set,
+// are decoded with the codec as they are.
+func WithCodec[T any](codec Codec[T]) OptionFunc[T] {
+	return func(c *Cache[T]) error {
+		if codec == nil {
+			return nil
+		}
+		name := codec.Name()
+		if name == "" || len(name) > 255 {
+			return exception.NewArgumentException("codec", name, "codec name must be 1 to 255 bytes long")
+		}
+		header := append(append(codecPrefix[:len(codecPrefix):len(codecPrefix)], byte(len(name))), name...)
+		c.encoder = func(value T) ([]byte, error) {
+			bs, err := codec.Encode(value)
+			if err != nil {
+				return nil, err
+			}
+			return append(header[:len(header):len(header)], bs...), nil
+		}
+		c.decoder = func(bs []byte) (T, error) {
+			if payload, ok := bytes.CutPrefix(bs, codecPrefix); ok {
+				if len(payload) == 0 || len(payload) < 1+int(payload[0]) {
+					return *new(T), NewCodecMismatchException(name, "")
+				}
+				end := 1 + int(payload[0])
+				if actual := string(payload[1:end]); actual != name {
+					return *new(T), NewCodecMismatchException(name, actual)
+				}
+				bs = payload[end:]
+			}
+			return codec.Decode(bs)
+		}
+		return nil
+	}
+}
+
+type codec[T any] struct {
+	name   string
+	encode func(value T) ([]byte, error)
+	decode func(data []byte) (T, error)
+}
+
+func (c codec[T]) Name() string {
+	return c.name
+}
+
+func (c codec[T]) Encode(value T) ([]byte, error) {
+	return c.encode(value)
+}
+
+func (c codec[T]) Decode(data []byte) (T, error) {
+	return c.decode(data)
+}
+
+// NewCodec creates a codec from a pair of functions.
+func NewCodec[T any](name string, encode func(value T) ([]byte, error), decode func(data []byte) (T, error)) Codec[T] {
+	return codec[T]{name: name, encode: encode, decode: decode}
+}
+
+// JSONCodec encodes values with encoding/json.
+func JSONCodec[T any]() Codec[T] {
+	return NewCodec("json", func(value T) ([]byte, error) {
+		return json.Marshal(value)
+	}, func(data []byte) (T, error) {
+		var value T
+		err := json.Unmarshal(data, &value)
+		return value, err
+	})
+}
+
+// GobCodec encodes values with encoding/gob.
+func GobCodec[T any]() Codec[T] {
+	return NewCodec("gob", func(value T) ([]byte, error) {
+		buf := new(bytes.Buffer)
+		if err := gob.NewEncoder(buf).Encode(value); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}, func(data []byte) (T, error) {
+		var value T
+		err := gob.NewDecoder(bytes.NewReader(data)).Decode(&value)
+		return value, err
+	})
+}
+
+// BytesCodec stores byte slices as they are.
+func BytesCodec() Codec[[]byte] {
+	return NewCodec("bytes", func(value []byte) ([]byte, error) {
+		return value, nil
+	}, func(data []byte) ([]byte, error) {
+		return bytes.Clone(data), nil
+	})
+}
+
+// StringCodec stores strings as they are.
+func StringCodec() Codec[string] {
+	return NewCodec("string", func(value string) ([]byte, error) {
+		return []byte(value), nil
+	}, func(data []byte) (string, error) {
+		return string(data), nil
+	})
+}
+
+// BinaryCodec encodes values implementing [encoding.BinaryMarshaler] and [encoding.BinaryUnmarshaler],
+// on either the value or its pointer.
+func BinaryCodec[T any]() Codec[T] {
+	return NewCodec("binary", func(value T) ([]byte, error) {
+		marshaler, ok := asInterface[encoding.BinaryMarshaler](&value)
+		if !ok {
+			return nil, unsupportedCodecType[T]("encoding.BinaryMarshaler")
+		}
+		return marshaler.MarshalBinary()
+	}, func(data []byte) (T, error) {
+		value := newValue[T]()
+		unmarshaler, ok := asInterface[encoding.BinaryUnmarshaler](&value)
+		if !ok {
+			return value, unsupportedCodecType[T]("encoding.BinaryUnmarshaler")
+		}
+		return value, unmarshaler.UnmarshalBinary(data)
+	})
+}
+
+// TextCodec encodes values implementing [encoding.TextMarshaler] and [encoding.TextUnmarshaler],
+// on either the value or its pointer.
+func TextCodec[T any]() Codec[T] {
+	return NewCodec("text", func(value T) ([]byte, error) {
+		marshaler, ok := asInterface[encoding.TextMarshaler](&value)
+		if !ok {
+			return nil, unsupportedCodecType[T]("encoding.TextMarshaler")
+		}
+		return marshaler.MarshalText()
+	}, func(data []byte) (T, error) {
+		value := newValue[T]()
+		unmarshaler, ok := asInterface[encoding.TextUnmarshaler](&value)
+		if !ok {
+			return value, unsupportedCodecType[T]("encoding.TextUnmarshaler")
+		}
+		return value, unmarshaler.UnmarshalText(data)
+	})
+}
+
+// ProtoCodec encodes protobuf messages which marshal themselves, such as the messages generated by gogo/protobuf.
+// T is the message pointer type, such as *pb.User. Messages generated by google.golang.org/protobuf do not have
+// such methods, use [NewCodec] with proto.Marshal and proto.Unmarshal for them.
+func ProtoCodec[T any]() Codec[T] {
+	return NewCodec("proto", func(value T) ([]byte, error) {
+		message, ok := any(value).(interface{ Marshal() ([]byte, error) })
+		if !ok {
+			return nil, unsupportedCodecType[T]("Marshal() ([]byte, error)")
+		}
+		return message.Marshal()
+	}, func(data []byte) (T, error) {
+		value := newValue[T]()
+		message, ok := any(value).(interface{ Unmarshal([]byte) error })
+		if !ok {
+			return value, unsupportedCodecType[T]("Unmarshal([]byte) error")
+		}
+		return value, message.Unmarshal(data)
+	})
+}
+
+// newValue returns the zero value of T, or a pointer to a new zero value if T is a pointer type.
+func newValue[T any]() T {
+	if typ := reflect.TypeOf((*T)(nil)).Elem(); typ.Kind() == reflect.Pointer {
+		return reflect.New(typ.Elem()).Interface().(T)
+	}
+	return *new(T)
+}
+
+// asInterface returns the value or its pointer as I.
+func asInterface[I any, T any](value *T) (I, bool) {
+	if i, ok := any(*value).(I); ok {
+		return i, true
+	}
+	i, ok := any(value).(I)
+	return i, ok
+}
+
+func unsupportedCodecType[T any](iface string) error {
+	return exception.NewArgumentException("T", reflect.TypeOf((*T)(nil)).Elem(), fmt.Sprintf("type does not implement %s", iface))
+}
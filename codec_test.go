@@ -0,0 +1,123 @@
+package cache
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type codecUser struct {
+	Name string
+	Age  int
+}
+
+// protoUser marshals itself like the messages generated by gogo/protobuf.
+type protoUser struct {
+	Name string
+}
+
+func (u *protoUser) Marshal() ([]byte, error) {
+	return []byte(u.Name), nil
+}
+
+func (u *protoUser) Unmarshal(data []byte) error {
+	u.Name = string(data)
+	return nil
+}
+
+func roundTrip[T any](t *testing.T, codec Codec[T], value T) T {
+	c, err := New[T](newMapStore(), WithCodec[T](codec))
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	assert.NoError(t, c.Set("key", value, 0))
+	got, err := c.Get("key")
+	assert.NoError(t, err)
+	return got
+}
+
+func TestCodec(t *testing.T) {
+	t.Run("json", func(t *testing.T) {
+		assert.Equal(t, codecUser{"gopi", 3}, roundTrip(t, JSONCodec[codecUser](), codecUser{"gopi", 3}))
+	})
+
+	t.Run("gob", func(t *testing.T) {
+		assert.Equal(t, codecUser{"gopi", 3}, roundTrip(t, GobCodec[codecUser](), codecUser{"gopi", 3}))
+	})
+
+	t.Run("bytes", func(t *testing.T) {
+		assert.Equal(t, []byte{0, 0xfd, 0xff}, roundTrip(t, BytesCodec(), []byte{0, 0xfd, 0xff}))
+	})
+
+	t.Run("string", func(t *testing.T) {
+		assert.Equal(t, "value", roundTrip(t, StringCodec(), "value"))
+	})
+
+	t.Run("binary", func(t *testing.T) {
+		now := time.Now().UTC().Truncate(time.Second)
+		assert.True(t, now.Equal(roundTrip(t, BinaryCodec[time.Time](), now)))
+	})
+
+	t.Run("text", func(t *testing.T) {
+		addr := netip.MustParseAddr("192.168.0.1")
+		assert.Equal(t, addr, roundTrip(t, TextCodec[netip.Addr](), addr))
+		c, _ := New[codecUser](newMapStore(), WithCodec[codecUser](TextCodec[codecUser]()))
+		assert.Error(t, c.Set("key", codecUser{}, 0))
+	})
+
+	t.Run("proto", func(t *testing.T) {
+		value := roundTrip(t, ProtoCodec[*protoUser](), &protoUser{Name: "gopi"})
+		assert.Equal(t, "gopi", value.Name)
+		c, _ := New[codecUser](newMapStore(), WithCodec[codecUser](ProtoCodec[codecUser]()))
+		assert.Error(t, c.Set("key", codecUser{}, 0))
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		store := newMapStore()
+		gobCache, _ := New[codecUser](store, WithCodec[codecUser](GobCodec[codecUser]()))
+		jsonCache, _ := New[codecUser](store, WithCodec[codecUser](JSONCodec[codecUser]()))
+		assert.NoError(t, gobCache.Set("key", codecUser{"gopi", 3}, 0))
+		_, err := jsonCache.Get("key")
+		var mismatch *CodecMismatchException
+		if assert.ErrorAs(t, err, &mismatch) {
+			assert.Equal(t, "json", mismatch.Expected())
+			assert.Equal(t, "gob", mismatch.Actual())
+		}
+	})
+
+	t.Run("legacy value", func(t *testing.T) {
+		store := newMapStore()
+		assert.NoError(t, store.Set("key", `{"Name":"gopi","Age":3}`, 0))
+		c, _ := New[codecUser](store, WithCodec[codecUser](JSONCodec[codecUser]()))
+		value, err := c.Get("key")
+		assert.NoError(t, err)
+		assert.Equal(t, codecUser{"gopi", 3}, value)
+	})
+
+	t.Run("binary legacy value", func(t *testing.T) {
+		store := newMapStore()
+		assert.NoError(t, store.Set("key", "\xfd\x04json", 0))
+		c, _ := New[[]byte](store, WithCodec[[]byte](BytesCodec()))
+		value, err := c.Get("key")
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("\xfd\x04json"), value)
+	})
+
+	t.Run("truncated envelope", func(t *testing.T) {
+		store := newMapStore()
+		c, _ := New[[]byte](store, WithCodec[[]byte](BytesCodec()))
+		for _, raw := range []string{envelopePrefix(envelopeCodec), envelopePrefix(envelopeCodec) + "\x05byt"} {
+			assert.NoError(t, store.Set("key", raw, 0))
+			_, err := c.Get("key")
+			var mismatch *CodecMismatchException
+			assert.ErrorAs(t, err, &mismatch)
+		}
+	})
+
+	t.Run("invalid name", func(t *testing.T) {
+		_, err := New[string](newMapStore(), WithCodec[string](NewCodec[string]("", nil, nil)))
+		assert.Error(t, err)
+	})
+}
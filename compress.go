@@ -0,0 +1,273 @@
+package cache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/gopi-frame/contract/cache"
+	"github.com/gopi-frame/exception"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression algorithms.
+const (
+	CompressionGzip   = "gzip"
+	CompressionZstd   = "zstd"
+	CompressionSnappy = "snappy"
+)
+
+// Headers of the values written by [CompressionCache].
+// Compressed values are wrapped in an envelope whose header byte tells the algorithm. Values below the threshold
+// are stored as they are, and values without the envelope are read as they are, so stores can be switched to
+// compression without invalidating existing values.
+const (
+	HeaderRaw    byte = 0x00
+	HeaderGzip   byte = 0x01
+	HeaderZstd   byte = 0x02
+	HeaderSnappy byte = 0x03
+)
+
+func init() {
+	RegisterMiddleware("compress", func(_ string, options map[string]any) (Middleware, error) {
+		config := new(CompressionConfig)
+		if err := DecodeConfig(options, config); err != nil {
+			return nil, err
+		}
+		return CompressionMiddleware(config)
+	})
+}
+
+// CompressionConfig is the compression config.
+type CompressionConfig struct {
+	// Algorithm is one of "gzip", "zstd" and "snappy", default is "gzip".
+	Algorithm string `json:"algorithm" yaml:"algorithm" toml:"algorithm" mapstructure:"algorithm"`
+	// Threshold is the size in bytes below which values are stored raw, default is 1024.
+	// A threshold of 0 compresses every value.
+	Threshold *int `json:"threshold" yaml:"threshold" toml:"threshold" mapstructure:"threshold"`
+	// Level is the compression level of gzip and zstd, default is the default level of the algorithm.
+	Level int `json:"level" yaml:"level" toml:"level" mapstructure:"level"`
+}
+
+// ApplyDefaults sets the default values of unset fields.
+func (c *CompressionConfig) ApplyDefaults() {
+	if c.Algorithm == "" {
+		c.Algorithm = CompressionGzip
+	}
+	if c.Threshold == nil {
+		threshold := 1024
+		c.Threshold = &threshold
+	}
+}
+
+var (
+	zstdDecoderOnce sync.Once
+	zstdDecoder     *zstd.Decoder
+	zstdDecoderErr  error
+)
+
+func decodeZstd(src []byte) ([]byte, error) {
+	zstdDecoderOnce.Do(func() {
+		zstdDecoder, zstdDecoderErr = zstd.NewReader(nil)
+	})
+	if zstdDecoderErr != nil {
+		return nil, zstdDecoderErr
+	}
+	return zstdDecoder.DecodeAll(src, nil)
+}
+
+// compressor compresses values with the configured algorithm and decompresses values of any algorithm.
+type compressor struct {
+	header    byte
+	threshold int
+	level     int
+	zstd      *zstd.Encoder
+}
+
+func newCompressor(config *CompressionConfig) (*compressor, error) {
+	config.ApplyDefaults()
+	c := &compressor{threshold: *config.Threshold, level: config.Level}
+	switch config.Algorithm {
+	case CompressionGzip:
+		c.header = HeaderGzip
+		if c.level == 0 {
+			c.level = gzip.DefaultCompression
+		}
+		if c.level < gzip.HuffmanOnly || c.level > gzip.BestCompression {
+			return nil, exception.NewArgumentException("level", config.Level, fmt.Sprintf("invalid gzip level %d", config.Level))
+		}
+	case CompressionZstd:
+		c.header = HeaderZstd
+		level := zstd.SpeedDefault
+		if c.level != 0 {
+			level = zstd.EncoderLevelFromZstd(c.level)
+		}
+		encoder, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(level))
+		if err != nil {
+			return nil, err
+		}
+		c.zstd = encoder
+	case CompressionSnappy:
+		c.header = HeaderSnappy
+	default:
+		return nil, exception.NewArgumentException("algorithm", config.Algorithm, fmt.Sprintf("unknown compression algorithm \"%s\"", config.Algorithm))
+	}
+	return c, nil
+}
+
+// compressionPrefix starts the envelopes of compressed values, it is followed by the header and the payload.
+var compressionPrefix = []byte(envelopePrefix(envelopeCompression))
+
+func (c *compressor) compress(value []byte) ([]byte, error) {
+	if len(value) < c.threshold {
+		if !bytes.HasPrefix(value, compressionPrefix) {
+			return value, nil
+		}
+		// wrapped so it is not read as an envelope
+		return append(compressionEnvelope(HeaderRaw), value...), nil
+	}
+	switch c.header {
+	case HeaderGzip:
+		buf := bytes.NewBuffer(compressionEnvelope(HeaderGzip))
+		w, err := gzip.NewWriterLevel(buf, c.level)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(value); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case HeaderZstd:
+		return c.zstd.EncodeAll(value, compressionEnvelope(HeaderZstd)), nil
+	default:
+		return append(compressionEnvelope(HeaderSnappy), snappy.Encode(nil, value)...), nil
+	}
+}
+
+// compressionEnvelope returns the envelope prefix of values with header.
+func compressionEnvelope(header byte) []byte {
+	return append(compressionPrefix[:len(compressionPrefix):len(compressionPrefix)], header)
+}
+
+func (c *compressor) decompress(value []byte) ([]byte, error) {
+	payload, ok := bytes.CutPrefix(value, compressionPrefix)
+	if !ok {
+		// below the threshold, or written before compression was enabled
+		return value, nil
+	}
+	if len(payload) == 0 {
+		return nil, exception.New("truncated compression envelope")
+	}
+	switch payload[0] {
+	case HeaderRaw:
+		return payload[1:], nil
+	case HeaderGzip:
+		r, err := gzip.NewReader(bytes.NewReader(payload[1:]))
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			_ = r.Close()
+		}()
+		return io.ReadAll(r)
+	case HeaderZstd:
+		return decodeZstd(payload[1:])
+	case HeaderSnappy:
+		return snappy.Decode(nil, payload[1:])
+	default:
+		return nil, exception.New(fmt.Sprintf("unknown compression header %#x", payload[0]))
+	}
+}
+
+// CompressionCache compresses the values of a store.
+type CompressionCache struct {
+	Forwarder
+	compressor *compressor
+}
+
+// NewCompressionCache creates a cache which compresses the values of store.
+func NewCompressionCache(store cache.Cache, config *CompressionConfig) (*CompressionCache, error) {
+	compressor, err := newCompressor(config)
+	if err != nil {
+		return nil, err
+	}
+	return &CompressionCache{
+		Forwarder:  Forwarder{Next: store},
+		compressor: compressor,
+	}, nil
+}
+
+// CompressionMiddleware returns a middleware which wraps stores with [NewCompressionCache].
+func CompressionMiddleware(config *CompressionConfig) (Middleware, error) {
+	compressor, err := newCompressor(config)
+	if err != nil {
+		return nil, err
+	}
+	return func(next cache.Cache) cache.Cache {
+		return &CompressionCache{
+			Forwarder:  Forwarder{Next: next},
+			compressor: compressor,
+		}
+	}, nil
+}
+
+func (c *CompressionCache) Get(key string) (string, error) {
+	value, err := c.Next.Get(key)
+	if err != nil {
+		return "", err
+	}
+	bs, err := c.compressor.decompress([]byte(value))
+	if err != nil {
+		return "", err
+	}
+	return string(bs), nil
+}
+
+func (c *CompressionCache) Set(key string, value string, expire time.Duration) error {
+	bs, err := c.compressor.compress([]byte(value))
+	if err != nil {
+		return err
+	}
+	return c.Next.Set(key, string(bs), expire)
+}
+
+func (c *CompressionCache) Load(key string, loader func() (string, error), expire time.Duration) (string, error) {
+	value, err := c.Next.Load(key, func() (string, error) {
+		value, err := loader()
+		if err != nil {
+			return "", err
+		}
+		bs, err := c.compressor.compress([]byte(value))
+		if err != nil {
+			return "", err
+		}
+		return string(bs), nil
+	}, expire)
+	if err != nil {
+		return "", err
+	}
+	bs, err := c.compressor.decompress([]byte(value))
+	if err != nil {
+		return "", err
+	}
+	return string(bs), nil
+}
+
+// WithCompression compresses the encoded values of the cache.
+func WithCompression[T any](config *CompressionConfig) OptionFunc[T] {
+	return func(c *Cache[T]) error {
+		store, err := NewCompressionCache(c.Cache, config)
+		if err != nil {
+			return err
+		}
+		c.Cache = store
+		return nil
+	}
+}
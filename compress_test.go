@@ -0,0 +1,162 @@
+package cache
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func compressionHeader(raw string) int {
+	payload, ok := unwrapEnvelope(envelopeCompression, raw)
+	if !ok || payload == "" {
+		return -1
+	}
+	return int(payload[0])
+}
+
+func TestCompressionCache(t *testing.T) {
+	large := strings.Repeat(`{"name":"gopi","tags":["cache","compression"]}`, 100)
+	binary := string([]byte{0x00, 0x01, 0x02, 0x03, 0xff, 0xfe}) + large
+
+	for _, algorithm := range []string{CompressionGzip, CompressionZstd, CompressionSnappy} {
+		t.Run(algorithm, func(t *testing.T) {
+			store := newMapStore()
+			c, err := NewCompressionCache(store, &CompressionConfig{Algorithm: algorithm})
+			if err != nil {
+				assert.FailNow(t, err.Error())
+			}
+			for _, value := range []string{"", "small", large, binary} {
+				assert.NoError(t, c.Set("key", value, 0))
+				got, err := c.Get("key")
+				assert.NoError(t, err)
+				assert.Equal(t, value, got)
+			}
+			raw, _ := store.Get("key")
+			assert.Less(t, len(raw), len(binary))
+
+			value, err := c.Load("loaded", func() (string, error) { return large, nil }, 0)
+			assert.NoError(t, err)
+			assert.Equal(t, large, value)
+			value, err = c.Load("loaded", func() (string, error) { return "", nil }, 0)
+			assert.NoError(t, err)
+			assert.Equal(t, large, value)
+		})
+	}
+
+	t.Run("threshold", func(t *testing.T) {
+		store := newMapStore()
+		threshold := 10
+		c, _ := NewCompressionCache(store, &CompressionConfig{Threshold: &threshold})
+		assert.NoError(t, c.Set("small", "value", 0))
+		raw, _ := store.Get("small")
+		assert.Equal(t, "value", raw)
+		assert.NoError(t, c.Set("large", large, 0))
+		raw, _ = store.Get("large")
+		assert.Equal(t, int(HeaderGzip), compressionHeader(raw))
+
+		// small values which look like an envelope are wrapped
+		envelope := string(compressionEnvelope(HeaderGzip)) + "v"
+		assert.NoError(t, c.Set("envelope", envelope, 0))
+		raw, _ = store.Get("envelope")
+		assert.Equal(t, int(HeaderRaw), compressionHeader(raw))
+		value, err := c.Get("envelope")
+		assert.NoError(t, err)
+		assert.Equal(t, envelope, value)
+	})
+
+	t.Run("always compress", func(t *testing.T) {
+		store := newMapStore()
+		threshold := 0
+		c, _ := NewCompressionCache(store, &CompressionConfig{Algorithm: CompressionSnappy, Threshold: &threshold})
+		assert.NoError(t, c.Set("key", "v", 0))
+		raw, _ := store.Get("key")
+		assert.Equal(t, int(HeaderSnappy), compressionHeader(raw))
+		value, err := c.Get("key")
+		assert.NoError(t, err)
+		assert.Equal(t, "v", value)
+	})
+
+	t.Run("mixed algorithms and legacy values", func(t *testing.T) {
+		store := newMapStore()
+		gzipCache, _ := NewCompressionCache(store, &CompressionConfig{Algorithm: CompressionGzip})
+		zstdCache, _ := NewCompressionCache(store, &CompressionConfig{Algorithm: CompressionZstd})
+		assert.NoError(t, gzipCache.Set("key", large, 0))
+		value, err := zstdCache.Get("key")
+		assert.NoError(t, err)
+		assert.Equal(t, large, value)
+		assert.NoError(t, store.Set("legacy", `{"legacy":true}`, 0))
+		value, err = zstdCache.Get("legacy")
+		assert.NoError(t, err)
+		assert.Equal(t, `{"legacy":true}`, value)
+	})
+
+	t.Run("binary legacy values", func(t *testing.T) {
+		store := newMapStore()
+		c, _ := NewCompressionCache(store, &CompressionConfig{})
+		for _, legacy := range []string{"\x00\x01\x02legacy", "\x01\x8b\x08legacy", "\x02", "\x03\x00", "\x1f\x8b\x08\x00"} {
+			assert.NoError(t, store.Set("legacy", legacy, 0))
+			value, err := c.Get("legacy")
+			assert.NoError(t, err)
+			assert.Equal(t, legacy, value)
+		}
+	})
+
+	t.Run("corrupted", func(t *testing.T) {
+		store := newMapStore()
+		c, _ := NewCompressionCache(store, &CompressionConfig{})
+		assert.NoError(t, store.Set("key", string(compressionEnvelope(HeaderGzip))+"garbage", 0))
+		_, err := c.Get("key")
+		assert.Error(t, err)
+		assert.NoError(t, store.Set("key", envelopePrefix(envelopeCompression)+"\x7fgarbage", 0))
+		_, err = c.Get("key")
+		assert.Error(t, err)
+		assert.NoError(t, store.Set("key", envelopePrefix(envelopeCompression), 0))
+		_, err = c.Get("key")
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		_, err := NewCompressionCache(newMapStore(), &CompressionConfig{Algorithm: "lz4"})
+		assert.Error(t, err)
+		_, err = NewCompressionCache(newMapStore(), &CompressionConfig{Level: 42})
+		assert.Error(t, err)
+	})
+
+	t.Run("typed cache", func(t *testing.T) {
+		type document struct {
+			Body string `json:"body"`
+		}
+		store := newMapStore()
+		c, err := New[document](store, WithCompression[document](&CompressionConfig{Algorithm: CompressionSnappy}))
+		if err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		assert.NoError(t, c.Set("key", document{Body: large}, 0))
+		raw, _ := store.Get("key")
+		assert.Equal(t, int(HeaderSnappy), compressionHeader(raw))
+		doc, err := c.Get("key")
+		assert.NoError(t, err)
+		assert.Equal(t, large, doc.Body)
+	})
+
+	t.Run("middleware", func(t *testing.T) {
+		manager, err := OpenCacheManager(map[string]any{
+			"stores": map[string]any{
+				"map": map[string]any{
+					"driver":      "test-map",
+					"middlewares": []any{map[string]any{"name": "compress", "algorithm": "zstd", "threshold": "8"}},
+				},
+			},
+		})
+		if err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		assert.NoError(t, manager.Set("key", large, 0))
+		value, err := manager.Get("key")
+		assert.NoError(t, err)
+		assert.Equal(t, large, value)
+		raw, _ := manager.GetStore("map").(*CompressionCache).Next.Get("key")
+		assert.Equal(t, int(HeaderZstd), compressionHeader(raw))
+	})
+}
@@ -0,0 +1,124 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/gopi-frame/exception"
+)
+
+// StrictKey is the reserved config key which makes [DecodeConfig] fail on unknown keys.
+const StrictKey = "strict"
+
+// ConfigDefaulter is implemented by driver configs to fill the default values after decoding.
+type ConfigDefaulter interface {
+	ApplyDefaults()
+}
+
+type decodeOptions struct {
+	aliases     map[string]string
+	errorUnused bool
+}
+
+// DecodeOption configures [DecodeConfig].
+type DecodeOption func(opts *decodeOptions)
+
+// WithAliases maps alternative keys, such as "table", to the keys of the config struct, such as "table_name".
+func WithAliases(aliases map[string]string) DecodeOption {
+	return func(opts *decodeOptions) {
+		opts.aliases = aliases
+	}
+}
+
+// ErrorUnused makes [DecodeConfig] fail on keys which do not match any config field.
+func ErrorUnused() DecodeOption {
+	return func(opts *decodeOptions) {
+		opts.errorUnused = true
+	}
+}
+
+// DecodeConfig decodes the config map of a driver into output.
+//
+// Keys match the field tags regardless of case, underscores and dashes, so "table_name", "tableName" and
+// "TableName" are the same key. Durations can be given as strings such as "5m", and file modes as octal
+// strings such as "0755". If the map has the reserved key "strict" set to true, unknown keys are reported
+// as errors. Keys which name the same field, such as an alias and its key, are reported as errors.
+// After decoding, defaults are applied if output implements [ConfigDefaulter].
+func DecodeConfig(input map[string]any, output any, opts ...DecodeOption) error {
+	options := new(decodeOptions)
+	for _, opt := range opts {
+		opt(options)
+	}
+	values := make(map[string]any, len(input))
+	// the input keys by field, to detect keys naming the same field
+	fields := make(map[string]string, len(input))
+	keys := make([]string, 0, len(input))
+	for key := range input {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := input[key]
+		if key == StrictKey {
+			if strict, ok := value.(bool); ok && strict {
+				options.errorUnused = true
+			}
+			continue
+		}
+		name := key
+		if alias, ok := options.aliases[key]; ok {
+			name = alias
+		}
+		field := normalizeConfigKey(name)
+		if other, ok := fields[field]; ok {
+			return exception.NewArgumentException(key, value, fmt.Sprintf("config key \"%s\" conflicts with \"%s\"", key, other))
+		}
+		fields[field] = key
+		values[name] = value
+	}
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			mapstructure.StringToTimeDurationHookFunc(),
+			stringToFileModeHookFunc(),
+			mapstructure.StringToSliceHookFunc(","),
+		),
+		ErrorUnused:      options.errorUnused,
+		WeaklyTypedInput: true,
+		MatchName: func(mapKey, fieldName string) bool {
+			return normalizeConfigKey(mapKey) == normalizeConfigKey(fieldName)
+		},
+		Result: output,
+	})
+	if err != nil {
+		return err
+	}
+	if err := decoder.Decode(values); err != nil {
+		return err
+	}
+	if defaulter, ok := output.(ConfigDefaulter); ok {
+		defaulter.ApplyDefaults()
+	}
+	return nil
+}
+
+func normalizeConfigKey(key string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(key))
+}
+
+func stringToFileModeHookFunc() mapstructure.DecodeHookFuncType {
+	return func(from reflect.Type, to reflect.Type, data any) (any, error) {
+		if from.Kind() != reflect.String || to != reflect.TypeOf(os.FileMode(0)) {
+			return data, nil
+		}
+		mode, err := strconv.ParseUint(data.(string), 8, 32)
+		if err != nil {
+			return nil, err
+		}
+		return os.FileMode(mode), nil
+	}
+}
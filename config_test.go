@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testConfig struct {
+	TableName string        `mapstructure:"table_name"`
+	Expire    time.Duration `mapstructure:"expire"`
+	FileMode  os.FileMode   `mapstructure:"fileMode"`
+	Prefix    string        `mapstructure:"prefix"`
+}
+
+func (c *testConfig) ApplyDefaults() {
+	if c.Prefix == "" {
+		c.Prefix = "cache"
+	}
+}
+
+func TestDecodeConfig(t *testing.T) {
+	t.Run("key styles", func(t *testing.T) {
+		for _, key := range []string{"table_name", "tableName", "TableName", "table-name"} {
+			var cfg testConfig
+			assert.NoError(t, DecodeConfig(map[string]any{key: "caches"}, &cfg))
+			assert.Equal(t, "caches", cfg.TableName, key)
+		}
+	})
+
+	t.Run("aliases", func(t *testing.T) {
+		var cfg testConfig
+		assert.NoError(t, DecodeConfig(map[string]any{"table": "caches"}, &cfg, WithAliases(map[string]string{"table": "table_name"})))
+		assert.Equal(t, "caches", cfg.TableName)
+	})
+
+	t.Run("conflicting keys", func(t *testing.T) {
+		var cfg testConfig
+		aliases := WithAliases(map[string]string{"table": "table_name"})
+		err := DecodeConfig(map[string]any{"table": "a", "table_name": "b"}, &cfg, aliases)
+		assert.ErrorContains(t, err, `config key "table_name" conflicts with "table"`)
+		assert.Error(t, DecodeConfig(map[string]any{"tableName": "a", "table_name": "b"}, &cfg))
+	})
+
+	t.Run("string values", func(t *testing.T) {
+		var cfg testConfig
+		assert.NoError(t, DecodeConfig(map[string]any{"expire": "5m", "file_mode": "0600"}, &cfg))
+		assert.Equal(t, time.Minute*5, cfg.Expire)
+		assert.Equal(t, os.FileMode(0600), cfg.FileMode)
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		var cfg testConfig
+		assert.NoError(t, DecodeConfig(map[string]any{}, &cfg))
+		assert.Equal(t, "cache", cfg.Prefix)
+	})
+
+	t.Run("unknown keys", func(t *testing.T) {
+		var cfg testConfig
+		assert.NoError(t, DecodeConfig(map[string]any{"unknown": 1}, &cfg))
+		assert.Error(t, DecodeConfig(map[string]any{"unknown": 1}, &cfg, ErrorUnused()))
+		assert.Error(t, DecodeConfig(map[string]any{"unknown": 1, StrictKey: true}, &cfg))
+		assert.NoError(t, DecodeConfig(map[string]any{"prefix": "p", StrictKey: true}, &cfg))
+	})
+}
@@ -1,62 +1,228 @@
 package cache
 
 import (
+	"context"
+	"io"
 	"sync"
 	"time"
 
 	"github.com/gopi-frame/contract/cache"
 )
 
+// DeferCache opens the store on first use.
+// If opening fails, every call returns the error until the next attempt, which is made after the backoff delay.
+// Once closed, every call returns [ErrCacheClosed].
 type DeferCache struct {
-	cache.Cache
+	driver  string
+	config  map[string]any
+	backoff func(attempt int) time.Duration
 
-	once   sync.Once
-	driver string
-	config map[string]any
+	mu       sync.Mutex
+	cache    cache.Cache
+	err      error
+	attempts int
+	retryAt  time.Time
+	// opening is closed when the attempt in progress finishes, it is nil if no attempt is in progress.
+	opening chan struct{}
+	// closed reports whether Close was called.
+	closed bool
 }
 
-func NewDeferCache(driver string, config map[string]any) *DeferCache {
-	return &DeferCache{
-		driver: driver,
-		config: config,
+// DeferOption configures [DeferCache].
+type DeferOption func(c *DeferCache)
+
+// WithBackoff sets the delay before the next attempt to open the store after the n-th failed attempt.
+func WithBackoff(backoff func(attempt int) time.Duration) DeferOption {
+	return func(c *DeferCache) {
+		if backoff == nil {
+			return
+		}
+		c.backoff = backoff
 	}
 }
 
-func (c *DeferCache) deferInit() {
-	c.once.Do(func() {
-		var err error
-		if c.Cache, err = Open(c.driver, c.config); err != nil {
-			panic(err)
+// ExponentialBackoff returns a backoff which starts at initial and doubles on every attempt up to max.
+func ExponentialBackoff(initial, max time.Duration) func(attempt int) time.Duration {
+	return func(attempt int) time.Duration {
+		delay := initial
+		for i := 1; i < attempt && delay < max; i++ {
+			delay *= 2
+		}
+		if delay > max {
+			delay = max
 		}
-	})
+		return delay
+	}
+}
+
+func NewDeferCache(driver string, config map[string]any, opts ...DeferOption) *DeferCache {
+	c := &DeferCache{
+		driver:  driver,
+		config:  config,
+		backoff: ExponentialBackoff(100*time.Millisecond, 30*time.Second),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// deferInit returns the store, opening it if needed.
+// The store is opened without holding the lock, so a slow open does not block Ready, Err and CheckHealth,
+// and concurrent callers wait for the attempt in progress instead of starting their own.
+func (c *DeferCache) deferInit() (cache.Cache, error) {
+	c.mu.Lock()
+	for {
+		if c.closed {
+			c.mu.Unlock()
+			return nil, ErrCacheClosed
+		}
+		if c.cache != nil {
+			store := c.cache
+			c.mu.Unlock()
+			return store, nil
+		}
+		if c.err != nil && time.Now().Before(c.retryAt) {
+			err := c.err
+			c.mu.Unlock()
+			return nil, err
+		}
+		if c.opening == nil {
+			break
+		}
+		opening := c.opening
+		c.mu.Unlock()
+		<-opening
+		c.mu.Lock()
+	}
+	opening := make(chan struct{})
+	c.opening = opening
+	c.mu.Unlock()
+
+	store, err := Open(c.driver, c.config)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.opening = nil
+	close(opening)
+	if c.closed {
+		if err != nil {
+			return nil, ErrCacheClosed
+		}
+		if closer, ok := store.(io.Closer); ok {
+			_ = closer.Close()
+		}
+		return nil, ErrCacheClosed
+	}
+	if err != nil {
+		c.attempts++
+		c.err = err
+		c.retryAt = time.Now().Add(c.backoff(c.attempts))
+		return nil, err
+	}
+	c.cache = store
+	c.err = nil
+	c.attempts = 0
+	return store, nil
+}
+
+// Ready reports whether the store has been opened.
+func (c *DeferCache) Ready() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cache != nil
+}
+
+// Err returns the error of the last failed attempt to open the store, or nil if it has been opened
+// or not been tried yet.
+func (c *DeferCache) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
 }
 
 func (c *DeferCache) Get(key string) (string, error) {
-	c.deferInit()
-	return c.Cache.Get(key)
+	store, err := c.deferInit()
+	if err != nil {
+		return "", err
+	}
+	return store.Get(key)
 }
 
 func (c *DeferCache) Set(key string, value string, expire time.Duration) error {
-	c.deferInit()
-	return c.Cache.Set(key, value, expire)
+	store, err := c.deferInit()
+	if err != nil {
+		return err
+	}
+	return store.Set(key, value, expire)
 }
 
 func (c *DeferCache) Load(key string, loader func() (value string, err error), expire time.Duration) (string, error) {
-	c.deferInit()
-	return c.Cache.Load(key, loader, expire)
+	store, err := c.deferInit()
+	if err != nil {
+		return "", err
+	}
+	return store.Load(key, loader, expire)
 }
 
 func (c *DeferCache) Delete(key string) error {
-	c.deferInit()
-	return c.Cache.Delete(key)
+	store, err := c.deferInit()
+	if err != nil {
+		return err
+	}
+	return store.Delete(key)
 }
 
 func (c *DeferCache) Has(key string) bool {
-	c.deferInit()
-	return c.Cache.Has(key)
+	store, err := c.deferInit()
+	if err != nil {
+		return false
+	}
+	return store.Has(key)
 }
 
 func (c *DeferCache) Clear() error {
-	c.deferInit()
-	return c.Cache.Clear()
+	store, err := c.deferInit()
+	if err != nil {
+		return err
+	}
+	return store.Clear()
+}
+
+// CheckHealth opens the store if needed and checks it, a store which cannot be opened is unhealthy.
+// Failed opens are retried after the backoff as usual, so health checks also recover the store once the backend is back.
+// It returns the error of ctx if opening takes longer than ctx allows.
+func (c *DeferCache) CheckHealth(ctx context.Context) error {
+	type result struct {
+		store cache.Cache
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		store, err := c.deferInit()
+		done <- result{store, err}
+	}()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case r := <-done:
+		if r.err != nil {
+			return r.err
+		}
+		return CheckHealth(ctx, r.store)
+	}
+}
+
+// Close closes the store if it has been opened and can be closed.
+// A store whose open is in progress is closed as soon as it is opened.
+func (c *DeferCache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	store := c.cache
+	c.cache = nil
+	if closer, ok := store.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
 }
@@ -0,0 +1,135 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeferCache(t *testing.T) {
+	t.Run("open on first use", func(t *testing.T) {
+		c := NewDeferCache("test-map", map[string]any{})
+		assert.False(t, c.Ready())
+		assert.Nil(t, c.Set("key", "value", time.Minute))
+		assert.True(t, c.Ready())
+		assert.Nil(t, c.Err())
+		value, err := c.Get("key")
+		assert.Nil(t, err)
+		assert.Equal(t, "value", value)
+	})
+
+	t.Run("return open error", func(t *testing.T) {
+		c := NewDeferCache("test-map", map[string]any{"fail": true})
+		assert.NotPanics(t, func() {
+			_, err := c.Get("key")
+			assert.ErrorIs(t, err, ErrCacheNotFound)
+		})
+		assert.ErrorIs(t, c.Set("key", "value", time.Minute), ErrCacheNotFound)
+		assert.ErrorIs(t, c.Delete("key"), ErrCacheNotFound)
+		assert.ErrorIs(t, c.Clear(), ErrCacheNotFound)
+		assert.False(t, c.Has("key"))
+		assert.False(t, c.Ready())
+		assert.ErrorIs(t, c.Err(), ErrCacheNotFound)
+	})
+
+	t.Run("retry after backoff", func(t *testing.T) {
+		failures := 2
+		var attempts []int
+		c := NewDeferCache("test-map", map[string]any{"failures": &failures}, WithBackoff(func(attempt int) time.Duration {
+			attempts = append(attempts, attempt)
+			return 20 * time.Millisecond
+		}))
+		assert.NotNil(t, c.Set("key", "value", time.Minute))
+		// still in backoff, the driver is not called again
+		assert.NotNil(t, c.Set("key", "value", time.Minute))
+		assert.Equal(t, 1, failures)
+		time.Sleep(30 * time.Millisecond)
+		assert.NotNil(t, c.Set("key", "value", time.Minute))
+		assert.Equal(t, 0, failures)
+		time.Sleep(30 * time.Millisecond)
+		assert.Nil(t, c.Set("key", "value", time.Minute))
+		assert.True(t, c.Ready())
+		assert.Nil(t, c.Err())
+		assert.Equal(t, []int{1, 2}, attempts)
+	})
+
+	t.Run("open without holding the lock", func(t *testing.T) {
+		block := make(chan struct{})
+		var opens atomic.Int32
+		c := NewDeferCache("test-map", map[string]any{"block": block, "opens": &opens})
+		var wg sync.WaitGroup
+		for i := 0; i < 3; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.Nil(t, c.Set("key", "value", time.Minute))
+			}()
+		}
+		assert.Eventually(t, func() bool {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			return c.opening != nil
+		}, time.Second, time.Millisecond)
+		assert.False(t, c.Ready())
+		assert.Nil(t, c.Err())
+		close(block)
+		wg.Wait()
+		assert.True(t, c.Ready())
+		assert.Equal(t, int32(1), opens.Load())
+	})
+
+	t.Run("close while opening", func(t *testing.T) {
+		block := make(chan struct{})
+		var opens atomic.Int32
+		c := NewDeferCache("test-map", map[string]any{"block": block, "opens": &opens})
+		done := make(chan error)
+		go func() {
+			done <- c.Set("key", "value", time.Minute)
+		}()
+		assert.Eventually(t, func() bool {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			return c.opening != nil
+		}, time.Second, time.Millisecond)
+		assert.Nil(t, c.Close())
+		close(block)
+		assert.ErrorIs(t, <-done, ErrCacheClosed)
+		assert.False(t, c.Ready())
+		assert.ErrorIs(t, c.Set("key", "value", time.Minute), ErrCacheClosed)
+		assert.Equal(t, int32(1), opens.Load())
+	})
+
+	t.Run("close", func(t *testing.T) {
+		c := NewDeferCache("test-map", map[string]any{})
+		assert.Nil(t, c.Set("key", "value", time.Minute))
+		store := c.cache.(*mapStore)
+		assert.Nil(t, c.Close())
+		assert.True(t, store.closed)
+		assert.False(t, c.Ready())
+		assert.ErrorIs(t, c.Set("key", "value", time.Minute), ErrCacheClosed)
+		_, err := c.Get("key")
+		assert.ErrorIs(t, err, ErrCacheClosed)
+		assert.False(t, c.Has("key"))
+		assert.Nil(t, c.Close())
+	})
+
+	t.Run("close before open", func(t *testing.T) {
+		var opens atomic.Int32
+		c := NewDeferCache("test-map", map[string]any{"opens": &opens})
+		assert.Nil(t, c.Close())
+		assert.ErrorIs(t, c.Set("key", "value", time.Minute), ErrCacheClosed)
+		assert.Zero(t, opens.Load())
+	})
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff(time.Second, 5*time.Second)
+	assert.Equal(t, time.Second, backoff(1))
+	assert.Equal(t, 2*time.Second, backoff(2))
+	assert.Equal(t, 4*time.Second, backoff(3))
+	assert.Equal(t, 5*time.Second, backoff(4))
+	assert.Equal(t, 5*time.Second, backoff(100))
+}
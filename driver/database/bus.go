@@ -0,0 +1,183 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/gopi-frame/cache"
+	"github.com/gopi-frame/exception"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Bus is an invalidation bus which stores invalidations in a table and polls it for new rows.
+//
+// Each subscription reads the rows after the greatest id it has handled. Auto-increment ids are allocated
+// before the publishing transaction commits, so rows may become visible out of id order. The ids skipped
+// by a row are therefore kept as gaps, whose rows are still handled if they appear within the lookback
+// window. The window is measured on the clock of the subscriber, so the clocks of publishers do not matter.
+type Bus struct {
+	db              *gorm.DB
+	tableName       string
+	interval        time.Duration
+	retention       time.Duration
+	lookback        time.Duration
+	cleanupInterval time.Duration
+
+	mu          sync.Mutex
+	lastCleanup time.Time
+
+	once sync.Once
+	done chan struct{}
+}
+
+// maxGaps is the maximum number of ids skipped by a row which are kept as gaps.
+const maxGaps = 1000
+
+// NewBus creates a new database invalidation bus.
+func NewBus(config *BusConfig) (*Bus, error) {
+	if config.DB == nil {
+		return nil, exception.NewEmptyArgumentException("db")
+	}
+	config.ApplyDefaults()
+	if !config.DB.Migrator().HasTable(config.TableName) {
+		if err := config.DB.Table(config.TableName).Migrator().CreateTable(new(InvalidationModel)); err != nil {
+			return nil, err
+		}
+	}
+	return &Bus{
+		db:              config.DB,
+		tableName:       config.TableName,
+		interval:        config.Interval,
+		retention:       config.Retention,
+		lookback:        config.Lookback,
+		cleanupInterval: config.CleanupInterval,
+		lastCleanup:     time.Now(),
+		done:            make(chan struct{}),
+	}, nil
+}
+
+func (b *Bus) Publish(ctx context.Context, message cache.Invalidation) error {
+	keys, err := json.Marshal(message.Keys)
+	if err != nil {
+		return err
+	}
+	model := &InvalidationModel{
+		Source:    message.Source,
+		Keys:      string(keys),
+		All:       message.All,
+		CreatedAt: time.Now(),
+	}
+	return b.db.WithContext(ctx).Table(b.tableName).Create(model).Error
+}
+
+// cursor is the position of a subscription in the invalidation table.
+type cursor struct {
+	// last is the greatest id handled.
+	last uint64
+	// gaps are the ids below last which have not been seen, with the time they were skipped.
+	gaps map[uint64]time.Time
+}
+
+func (b *Bus) Subscribe(ctx context.Context, handler func(message cache.Invalidation)) error {
+	// the invalidations published before the subscription are not handled
+	cur := &cursor{gaps: make(map[uint64]time.Time)}
+	if err := b.db.WithContext(ctx).Table(b.tableName).Select("COALESCE(MAX(id), 0)").Scan(&cur.last).Error; err != nil {
+		return err
+	}
+	go func() {
+		ticker := time.NewTicker(b.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-b.done:
+				return
+			case <-ticker.C:
+				b.poll(ctx, cur, handler)
+				b.cleanup(ctx)
+			}
+		}
+	}()
+	return nil
+}
+
+// recent returns the invalidations after the cursor and those filling its gaps, and advances the cursor.
+// Gaps older than the lookback window are given up.
+func (b *Bus) recent(ctx context.Context, cur *cursor) ([]InvalidationModel, error) {
+	floor := cur.last
+	for id := range cur.gaps {
+		if id <= floor {
+			floor = id - 1
+		}
+	}
+	var models []InvalidationModel
+	if err := b.db.WithContext(ctx).Table(b.tableName).Where(clause.Gt{Column: clause.Column{Name: "id"}, Value: floor}).Order("id").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	fresh := models[:0]
+	for _, model := range models {
+		if model.ID > cur.last {
+			from := cur.last + 1
+			if model.ID-from > maxGaps {
+				from = model.ID - maxGaps
+			}
+			for id := from; id < model.ID; id++ {
+				cur.gaps[id] = now
+			}
+			cur.last = model.ID
+		} else if _, ok := cur.gaps[model.ID]; ok {
+			delete(cur.gaps, model.ID)
+		} else {
+			continue
+		}
+		fresh = append(fresh, model)
+	}
+	for id, skipped := range cur.gaps {
+		if now.Sub(skipped) > b.lookback {
+			delete(cur.gaps, id)
+		}
+	}
+	return fresh, nil
+}
+
+func (b *Bus) poll(ctx context.Context, cur *cursor, handler func(message cache.Invalidation)) {
+	models, err := b.recent(ctx, cur)
+	if err != nil {
+		return
+	}
+	for _, model := range models {
+		message := cache.Invalidation{
+			Source: model.Source,
+			All:    model.All,
+		}
+		if err := json.Unmarshal([]byte(model.Keys), &message.Keys); err != nil {
+			continue
+		}
+		handler(message)
+	}
+}
+
+// cleanup deletes the invalidations older than the retention, at most once per cleanup interval.
+func (b *Bus) cleanup(ctx context.Context) {
+	b.mu.Lock()
+	if time.Since(b.lastCleanup) < b.cleanupInterval {
+		b.mu.Unlock()
+		return
+	}
+	b.lastCleanup = time.Now()
+	b.mu.Unlock()
+	b.db.WithContext(ctx).Table(b.tableName).Where(clause.Lt{Column: clause.Column{Name: "created_at"}, Value: time.Now().Add(-b.retention)}).Delete(new(InvalidationModel))
+}
+
+// Close stops all subscriptions, the database connection is left open.
+func (b *Bus) Close() error {
+	b.once.Do(func() {
+		close(b.done)
+	})
+	return nil
+}
@@ -0,0 +1,165 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gopi-frame/cache"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestBus(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	bus, err := NewBus(&BusConfig{
+		DB:       db,
+		Interval: time.Millisecond * 50,
+	})
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	defer func() {
+		_ = bus.Close()
+	}()
+	received := make(chan cache.Invalidation, 1)
+	if err := bus.Subscribe(context.Background(), func(message cache.Invalidation) {
+		received <- message
+	}); err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	if err := bus.Publish(context.Background(), cache.Invalidation{
+		Source: "peer",
+		Keys:   []string{"key1", "key2"},
+	}); err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	select {
+	case message := <-received:
+		assert.Equal(t, "peer", message.Source)
+		assert.Equal(t, []string{"key1", "key2"}, message.Keys)
+		assert.False(t, message.All)
+	case <-time.After(time.Second):
+		assert.FailNow(t, "invalidation not received")
+	}
+}
+
+func TestBus_LateCommit(t *testing.T) {
+	db := openGCTestDB(t, "bus_late")
+	bus, err := NewBus(&BusConfig{
+		DB:       db,
+		Interval: time.Millisecond * 20,
+	})
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	defer func() {
+		_ = bus.Close()
+	}()
+	received := make(chan cache.Invalidation, 2)
+	if err := bus.Subscribe(context.Background(), func(message cache.Invalidation) {
+		received <- message
+	}); err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	receive := func() cache.Invalidation {
+		select {
+		case message := <-received:
+			return message
+		case <-time.After(time.Second):
+			assert.FailNow(t, "invalidation not received")
+			return cache.Invalidation{}
+		}
+	}
+	// a row with a smaller id which becomes visible after a row with a greater id
+	for _, model := range []InvalidationModel{
+		{ID: 100, Source: "peer", Keys: `["late"]`, CreatedAt: time.Now()},
+		{ID: 50, Source: "peer", Keys: `["early"]`, CreatedAt: time.Now()},
+	} {
+		if err := db.Table("cache_invalidations").Create(&model).Error; err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		message := receive()
+		assert.Equal(t, model.Keys, `["`+strings.Join(message.Keys, `","`)+`"]`)
+	}
+	select {
+	case message := <-received:
+		assert.Fail(t, "invalidation received twice", message.Keys)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestBus_Cleanup(t *testing.T) {
+	db := openGCTestDB(t, "bus_cleanup")
+	bus, err := NewBus(&BusConfig{
+		DB:              db,
+		Interval:        time.Millisecond * 10,
+		Lookback:        time.Millisecond * 10,
+		Retention:       time.Minute,
+		CleanupInterval: time.Millisecond * 10,
+	})
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	defer func() {
+		_ = bus.Close()
+	}()
+	old := InvalidationModel{Source: "peer", Keys: `[]`, CreatedAt: time.Now().Add(-time.Hour)}
+	if err := db.Table("cache_invalidations").Create(&old).Error; err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	assert.NoError(t, bus.Publish(context.Background(), cache.Invalidation{Source: "peer", Keys: []string{"key"}}))
+	if err := bus.Subscribe(context.Background(), func(cache.Invalidation) {}); err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	assert.Eventually(t, func() bool {
+		var count int64
+		db.Table("cache_invalidations").Count(&count)
+		return count == 1
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestBus_ClockSkew(t *testing.T) {
+	db := openGCTestDB(t, "bus_skew")
+	bus, err := NewBus(&BusConfig{
+		DB:       db,
+		Interval: time.Millisecond * 20,
+		Lookback: time.Millisecond * 20,
+	})
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	defer func() {
+		_ = bus.Close()
+	}()
+	received := make(chan cache.Invalidation, 1)
+	if err := bus.Subscribe(context.Background(), func(message cache.Invalidation) {
+		received <- message
+	}); err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	// published by an instance whose clock is an hour behind
+	skewed := InvalidationModel{Source: "peer", Keys: `["key"]`, CreatedAt: time.Now().Add(-time.Hour)}
+	if err := db.Table("cache_invalidations").Create(&skewed).Error; err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	select {
+	case message := <-received:
+		assert.Equal(t, []string{"key"}, message.Keys)
+	case <-time.After(time.Second):
+		assert.FailNow(t, "invalidation not received")
+	}
+}
+
+func TestNewBus(t *testing.T) {
+	_, err := NewBus(&BusConfig{})
+	assert.Error(t, err)
+}
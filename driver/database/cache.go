@@ -1,9 +1,13 @@
 package database
 
 import (
+	"context"
 	"errors"
 	"github.com/gopi-frame/cache"
+	"github.com/gopi-frame/exception"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"sync"
 	"time"
 )
 
@@ -11,53 +15,74 @@ type Cache struct {
 	db        *gorm.DB
 	prefix    string
 	expire    time.Duration
+	sliding   bool
 	tableName string
+
+	gcInterval  time.Duration
+	gcBatchSize int
+	gcLease     bool
+	gcLeaseTTL  time.Duration
+	onGCError   func(err error)
+	owner       string
+
+	once sync.Once
+	done chan struct{}
 }
 
+// New creates a database cache, it panics if the cache table can not be migrated.
 func New(config *Config) *Cache {
-	if config.DB == nil {
-		panic("db is required")
-	}
-	if config.Prefix == "" {
-		config.Prefix = "cache"
-	}
-	if config.Expire <= 0 {
-		config.Expire = time.Hour * 72
+	c, err := newCache(config)
+	if err != nil {
+		panic(err)
 	}
-	if config.TableName == "" {
-		config.TableName = "caches"
+	return c
+}
+
+func newCache(config *Config) (*Cache, error) {
+	if config.DB == nil {
+		return nil, exception.NewEmptyArgumentException("db")
 	}
-	if !config.DB.Migrator().HasTable(config.TableName) {
-		if err := config.DB.Table(config.TableName).Migrator().CreateTable(new(CacheModel)); err != nil {
-			panic(err)
-		}
+	config.ApplyDefaults()
+	if err := Migrate(config.DB, config.TableName); err != nil {
+		return nil, err
 	}
 	c := &Cache{
-		db:        config.DB,
-		prefix:    config.Prefix,
-		expire:    config.Expire,
-		tableName: config.TableName,
+		db:          config.DB,
+		prefix:      config.Prefix,
+		expire:      config.Expire,
+		sliding:     config.Sliding,
+		tableName:   config.TableName,
+		gcInterval:  config.GCInterval,
+		gcBatchSize: config.GCBatchSize,
+		gcLease:     config.GCLease,
+		gcLeaseTTL:  config.GCLeaseTTL,
+		onGCError:   config.OnGCError,
+		done:        make(chan struct{}),
+	}
+	if config.GCEnabled == nil || *config.GCEnabled {
+		if c.gcLease {
+			if err := c.prepareLease(); err != nil {
+				return nil, err
+			}
+		}
+		go c.gc()
 	}
-	go c.gc()
-	return c
+	return c, nil
 }
 
 func (c *Cache) buildKey(key string) string {
 	return c.prefix + ":" + key
 }
 
-func (c *Cache) gc() {
-	for {
-		time.Sleep(time.Minute)
-		if err := c.db.Table(c.tableName).Where("expire < ?", time.Now()).Delete(&CacheModel{}).Error; err != nil {
-			panic(err)
-		}
-	}
+// whereKey builds the condition on the key column, which is a reserved word in some dialects
+// and has to be quoted by the dialector.
+func (c *Cache) whereKey(key string) clause.Eq {
+	return clause.Eq{Column: clause.Column{Name: "key"}, Value: c.buildKey(key)}
 }
 
 func (c *Cache) Get(key string) (string, error) {
 	var model = new(CacheModel)
-	if err := c.db.Table(c.tableName).Where("key = ?", c.buildKey(key)).First(&model).Error; err != nil {
+	if err := c.db.Table(c.tableName).Where(c.whereKey(key)).First(&model).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return "", cache.ErrCacheNotFound
 		}
@@ -69,19 +94,51 @@ func (c *Cache) Get(key string) (string, error) {
 		}()
 		return "", cache.ErrCacheNotFound
 	}
-	return model.Value, nil
+	if c.sliding {
+		if err := c.touch(key, model); err != nil {
+			return "", err
+		}
+	}
+	return string(model.Value), nil
+}
+
+// touch extends the expire time of model by the expire time it was set with, which is the time
+// between its update and its expiry. The expire time is checked by the update, so values which
+// expire in the meantime are not extended.
+func (c *Cache) touch(key string, model *CacheModel) error {
+	expire := model.Expire.Sub(model.UpdatedAt)
+	if model.UpdatedAt.IsZero() || expire <= 0 {
+		expire = c.expire
+	}
+	now := time.Now()
+	result := c.db.Table(c.tableName).
+		Where(c.whereKey(key)).
+		Where(clause.Gt{Column: clause.Column{Name: "expire"}, Value: now}).
+		Updates(map[string]any{"expire": now.Add(expire), "updated_at": now})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return cache.ErrCacheNotFound
+	}
+	return nil
 }
 
 func (c *Cache) Set(key string, value string, expire time.Duration) error {
 	if expire <= 0 {
 		expire = c.expire
 	}
+	now := time.Now()
 	model := &CacheModel{
-		Key:    c.buildKey(key),
-		Value:  value,
-		Expire: time.Now().Add(expire),
-	}
-	return c.db.Table(c.tableName).Save(model).Error
+		Key:       c.buildKey(key),
+		Value:     []byte(value),
+		Expire:    now.Add(expire),
+		UpdatedAt: now,
+	}
+	return c.db.Table(c.tableName).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "expire", "updated_at"}),
+	}).Create(model).Error
 }
 
 func (c *Cache) Load(key string, loader func() (string, error), expire time.Duration) (string, error) {
@@ -100,7 +157,7 @@ func (c *Cache) Load(key string, loader func() (string, error), expire time.Dura
 
 func (c *Cache) Has(key string) bool {
 	var model = new(CacheModel)
-	if err := c.db.Table(c.tableName).Where("key = ?", c.buildKey(key)).First(&model).Error; err != nil {
+	if err := c.db.Table(c.tableName).Where(c.whereKey(key)).First(&model).Error; err != nil {
 		return false
 	}
 	if model.Expire.Before(time.Now()) {
@@ -113,9 +170,26 @@ func (c *Cache) Has(key string) bool {
 }
 
 func (c *Cache) Delete(key string) error {
-	return c.db.Table(c.tableName).Where("key = ?", c.buildKey(key)).Delete(new(CacheModel)).Error
+	return c.db.Table(c.tableName).Where(c.whereKey(key)).Delete(new(CacheModel)).Error
 }
 
 func (c *Cache) Clear() error {
-	return c.db.Table(c.tableName).Where("key LIKE ?", c.prefix+":%").Delete(new(CacheModel)).Error
+	return c.db.Table(c.tableName).Where(clause.Like{Column: clause.Column{Name: "key"}, Value: c.prefix + ":%"}).Delete(new(CacheModel)).Error
+}
+
+// CheckHealth pings the database.
+func (c *Cache) CheckHealth(ctx context.Context) error {
+	db, err := c.db.DB()
+	if err != nil {
+		return err
+	}
+	return db.PingContext(ctx)
+}
+
+// Close stops the garbage collection, the database connection is left open.
+func (c *Cache) Close() error {
+	c.once.Do(func() {
+		close(c.done)
+	})
+	return nil
 }
@@ -1,12 +1,16 @@
 package database
 
 import (
+	"context"
+	"fmt"
 	"github.com/gopi-frame/cache"
 	cc "github.com/gopi-frame/contract/cache"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -150,3 +154,75 @@ func TestCache_Clear(t *testing.T) {
 	assert.False(t, testCache.Has("key"))
 	assert.False(t, testCache.Has("key2"))
 }
+
+func TestCache_SetConcurrently(t *testing.T) {
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- testCache.Set("key", fmt.Sprintf("value%d", i), 0)
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+	assert.True(t, testCache.Has("key"))
+}
+
+func TestCache_CheckHealth(t *testing.T) {
+	checker, ok := testCache.(cache.HealthChecker)
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.NoError(t, checker.CheckHealth(context.Background()))
+}
+
+func TestCache_Compression(t *testing.T) {
+	threshold := 1
+	c, err := cache.NewCompressionCache(testCache, &cache.CompressionConfig{Algorithm: cache.CompressionZstd, Threshold: &threshold})
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	value := strings.Repeat("compressed value ", 1000)
+	assert.NoError(t, c.Set("compressed", value, time.Minute))
+	got, err := c.Get("compressed")
+	assert.NoError(t, err)
+	assert.Equal(t, value, got)
+}
+
+func TestCache_Sliding(t *testing.T) {
+	db := openGCTestDB(t, "sliding")
+	gcEnabled := false
+	c := New(&Config{DB: db, Expire: time.Minute, Sliding: true, GCEnabled: &gcEnabled})
+	if err := c.Set("key", "value", time.Hour); err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	expire := func(key string) time.Time {
+		var model CacheModel
+		if err := db.Table(c.tableName).Where(c.whereKey(key)).First(&model).Error; err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		return model.Expire
+	}
+	// half of the expire time has passed
+	past := time.Now().Add(-30 * time.Minute)
+	if err := db.Table(c.tableName).Where(c.whereKey("key")).Updates(map[string]any{"expire": past.Add(time.Hour), "updated_at": past}).Error; err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	value, err := c.Get("key")
+	assert.NoError(t, err)
+	assert.Equal(t, "value", value)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), expire("key"), time.Second)
+
+	t.Run("expired in the meantime", func(t *testing.T) {
+		if err := db.Table(c.tableName).Where(c.whereKey("key")).Update("expire", time.Now().Add(-time.Second)).Error; err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		assert.ErrorIs(t, c.touch("key", &CacheModel{Expire: time.Now().Add(time.Minute), UpdatedAt: time.Now()}), cache.ErrCacheNotFound)
+		assert.True(t, expire("key").Before(time.Now()))
+	})
+}
@@ -12,6 +12,81 @@ type Config struct {
 	Prefix string `json:"prefix" yaml:"prefix" toml:"prefix" mapstructure:"prefix"`
 	// Expire is the default cache expire time, default is 72 hour.
 	Expire time.Duration `json:"expire" yaml:"expire" toml:"expire" mapstructure:"expire"`
+	// Sliding makes Get extend the expire time of values by the expire time they were set with.
+	Sliding bool `json:"sliding" yaml:"sliding" toml:"sliding" mapstructure:"sliding"`
 	// Table is the cache table name.
 	TableName string `json:"table_name" yaml:"table_name" toml:"table_name" mapstructure:"table_name"`
+	// GCEnabled enables the garbage collection of expired caches, default is true.
+	GCEnabled *bool `json:"gc_enabled" yaml:"gc_enabled" toml:"gc_enabled" mapstructure:"gc_enabled"`
+	// GCInterval is the interval between garbage collections, default is 1 minute.
+	GCInterval time.Duration `json:"gc_interval" yaml:"gc_interval" toml:"gc_interval" mapstructure:"gc_interval"`
+	// GCBatchSize is the maximum number of caches deleted by one statement, default is 1000.
+	GCBatchSize int `json:"gc_batch_size" yaml:"gc_batch_size" toml:"gc_batch_size" mapstructure:"gc_batch_size"`
+	// GCLease makes only the instance holding the gc lease of the table collect garbage.
+	GCLease bool `json:"gc_lease" yaml:"gc_lease" toml:"gc_lease" mapstructure:"gc_lease"`
+	// GCLeaseTTL is how long a gc lease is held without renewal, default is twice the gc interval.
+	GCLeaseTTL time.Duration `json:"gc_lease_ttl" yaml:"gc_lease_ttl" toml:"gc_lease_ttl" mapstructure:"gc_lease_ttl"`
+	// OnGCError is called with the errors of garbage collections, they are ignored if not set.
+	OnGCError func(err error) `json:"-" yaml:"-" toml:"-" mapstructure:"on_gc_error"`
+}
+
+// ApplyDefaults sets the default values of unset fields.
+func (c *Config) ApplyDefaults() {
+	if c.Prefix == "" {
+		c.Prefix = "cache"
+	}
+	if c.Expire <= 0 {
+		c.Expire = time.Hour * 72
+	}
+	if c.TableName == "" {
+		c.TableName = "caches"
+	}
+	if c.GCInterval <= 0 {
+		c.GCInterval = time.Minute
+	}
+	if c.GCBatchSize <= 0 {
+		c.GCBatchSize = 1000
+	}
+	if c.GCLeaseTTL <= 0 {
+		c.GCLeaseTTL = c.GCInterval * 2
+	}
+}
+
+// BusConfig is the database invalidation bus config.
+type BusConfig struct {
+	// DB is the database connection.
+	DB *gorm.DB `json:"db" yaml:"db" toml:"db" mapstructure:"db"`
+	// TableName is the invalidation table name, default is "cache_invalidations".
+	TableName string `json:"table_name" yaml:"table_name" toml:"table_name" mapstructure:"table_name"`
+	// Interval is the polling interval, default is 1 second.
+	Interval time.Duration `json:"interval" yaml:"interval" toml:"interval" mapstructure:"interval"`
+	// Retention is how long published invalidations are kept, default is 1 hour.
+	Retention time.Duration `json:"retention" yaml:"retention" toml:"retention" mapstructure:"retention"`
+	// Lookback is how long the ids skipped by a row are waited for, so rows committed after rows with greater ids
+	// are still received, default is 1 minute. It should exceed the longest publishing transaction.
+	Lookback time.Duration `json:"lookback" yaml:"lookback" toml:"lookback" mapstructure:"lookback"`
+	// CleanupInterval is the interval between deletions of invalidations older than the retention, default is 10 minutes.
+	CleanupInterval time.Duration `json:"cleanup_interval" yaml:"cleanup_interval" toml:"cleanup_interval" mapstructure:"cleanup_interval"`
+}
+
+// ApplyDefaults sets the default values of unset fields.
+func (c *BusConfig) ApplyDefaults() {
+	if c.TableName == "" {
+		c.TableName = "cache_invalidations"
+	}
+	if c.Interval <= 0 {
+		c.Interval = time.Second
+	}
+	if c.Retention <= 0 {
+		c.Retention = time.Hour
+	}
+	if c.Lookback <= 0 {
+		c.Lookback = time.Minute
+	}
+	if c.Retention < c.Lookback {
+		c.Retention = c.Lookback
+	}
+	if c.CleanupInterval <= 0 {
+		c.CleanupInterval = 10 * time.Minute
+	}
 }
@@ -0,0 +1,120 @@
+package database
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/driver/sqlserver"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// sqlRecorder records the SQL of every statement built through gorm.
+type sqlRecorder struct {
+	mu  sync.Mutex
+	sql []string
+}
+
+func (r *sqlRecorder) register(db *gorm.DB) error {
+	record := func(tx *gorm.DB) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.sql = append(r.sql, tx.Statement.SQL.String())
+	}
+	if err := db.Callback().Query().After("gorm:query").Register("test:record", record); err != nil {
+		return err
+	}
+	if err := db.Callback().Create().After("gorm:create").Register("test:record", record); err != nil {
+		return err
+	}
+	return db.Callback().Delete().After("gorm:delete").Register("test:record", record)
+}
+
+// record returns the first statement built while running fn.
+func (r *sqlRecorder) record(fn func()) string {
+	r.mu.Lock()
+	r.sql = nil
+	r.mu.Unlock()
+	fn()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.sql) == 0 {
+		return ""
+	}
+	return r.sql[0]
+}
+
+func TestDialects(t *testing.T) {
+	dialects := []struct {
+		name      string
+		dialector gorm.Dialector
+		where     string
+		upsert    string
+		like      string
+	}{
+		{
+			name:      "sqlite",
+			dialector: sqlite.Open("file::memory:"),
+			where:     "WHERE `key` = ",
+			upsert:    "ON CONFLICT (`key`) DO UPDATE SET `value`=`excluded`.`value`,`expire`=`excluded`.`expire`",
+			like:      "WHERE `key` LIKE ",
+		},
+		{
+			name:      "postgres",
+			dialector: postgres.New(postgres.Config{DSN: "host=localhost user=gopi dbname=gopi"}),
+			where:     `WHERE "key" = $1`,
+			upsert:    `ON CONFLICT ("key") DO UPDATE SET "value"="excluded"."value","expire"="excluded"."expire"`,
+			like:      `WHERE "key" LIKE $1`,
+		},
+		{
+			name:      "mysql",
+			dialector: mysql.New(mysql.Config{DSN: "gopi@tcp(localhost:3306)/gopi", SkipInitializeWithVersion: true}),
+			where:     "WHERE `key` = ",
+			upsert:    "ON DUPLICATE KEY UPDATE `value`=VALUES(`value`),`expire`=VALUES(`expire`)",
+			like:      "WHERE `key` LIKE ",
+		},
+		{
+			name:      "sqlserver",
+			dialector: sqlserver.Open("sqlserver://gopi@localhost:1433?database=gopi"),
+			where:     `WHERE "key" = @p1`,
+			upsert:    `MERGE INTO "caches" USING`,
+			like:      `WHERE "key" LIKE @p1`,
+		},
+	}
+	for _, dialect := range dialects {
+		t.Run(dialect.name, func(t *testing.T) {
+			db, err := gorm.Open(dialect.dialector, &gorm.Config{
+				DryRun:                 true,
+				DisableAutomaticPing:   true,
+				SkipDefaultTransaction: true,
+				Logger:                 logger.Default.LogMode(logger.Silent),
+			})
+			if err != nil {
+				assert.FailNow(t, err.Error())
+			}
+			recorder := new(sqlRecorder)
+			if err := recorder.register(db); err != nil {
+				assert.FailNow(t, err.Error())
+			}
+			c := &Cache{db: db, prefix: "cache", expire: time.Minute, tableName: "caches"}
+
+			assert.Contains(t, recorder.record(func() {
+				_, _ = c.Get("key")
+			}), dialect.where)
+			assert.Contains(t, recorder.record(func() {
+				_ = c.Delete("key")
+			}), dialect.where)
+			assert.Contains(t, recorder.record(func() {
+				_ = c.Set("key", "value", 0)
+			}), dialect.upsert)
+			assert.Contains(t, recorder.record(func() {
+				_ = c.Clear()
+			}), dialect.like)
+		})
+	}
+}
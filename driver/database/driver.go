@@ -1,7 +1,6 @@
 package database
 
 import (
-	"github.com/go-viper/mapstructure/v2"
 	"github.com/gopi-frame/cache"
 	cc "github.com/gopi-frame/contract/cache"
 	"github.com/gopi-frame/exception"
@@ -20,15 +19,14 @@ type Driver struct{}
 
 func (d *Driver) Open(config map[string]any) (cc.Cache, error) {
 	var cfg Config
-	err := mapstructure.Decode(config, &cfg)
+	err := cache.DecodeConfig(config, &cfg, cache.WithAliases(map[string]string{"table": "table_name"}))
 	if err != nil {
 		return nil, err
 	}
 	if cfg.DB == nil {
 		return nil, exception.NewArgumentException("db", cfg.DB, "db is required")
 	}
-	c := New(&cfg)
-	return c, nil
+	return newCache(&cfg)
 }
 
 func Open(config map[string]any) (cc.Cache, error) {
@@ -30,4 +30,40 @@ func TestOpenT(t *testing.T) {
 		})
 		assert.Nil(t, err)
 	})
+
+	t.Run("migration error", func(t *testing.T) {
+		db, err := gorm.Open(sqlite.Open("file:open_migration_error?mode=memory&cache=shared"), &gorm.Config{
+			Logger: logger.Default.LogMode(logger.Silent),
+		})
+		if err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		sqlDB, err := db.DB()
+		if err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		assert.NoError(t, sqlDB.Close())
+		_, err = OpenT[any](map[string]any{
+			"db": db,
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("lease error", func(t *testing.T) {
+		db, err := gorm.Open(sqlite.Open("file:open_lease_error?mode=memory&cache=shared"), &gorm.Config{
+			Logger: logger.Default.LogMode(logger.Silent),
+		})
+		if err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		defer func(name string) {
+			LeaseTableName = name
+		}(LeaseTableName)
+		LeaseTableName = "invalid lease\x00"
+		_, err = OpenT[any](map[string]any{
+			"db":       db,
+			"gc_lease": true,
+		})
+		assert.Error(t, err)
+	})
 }
@@ -0,0 +1,112 @@
+package database
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// LeaseTableName is the table which holds the gc leases of cache tables.
+var LeaseTableName = "cache_leases"
+
+func (c *Cache) gc() {
+	ticker := time.NewTicker(c.gcInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			if err := c.collect(); err != nil && c.onGCError != nil {
+				c.onGCError(err)
+			}
+		}
+	}
+}
+
+// collect deletes expired caches in batches, so that huge tables are not locked by one statement.
+func (c *Cache) collect() error {
+	if c.gcLease {
+		acquired, err := c.acquireLease()
+		if err != nil || !acquired {
+			return err
+		}
+	}
+	for {
+		select {
+		case <-c.done:
+			return nil
+		default:
+		}
+		now := time.Now()
+		var keys []string
+		if err := c.db.Table(c.tableName).
+			Where(clause.Lt{Column: clause.Column{Name: "expire"}, Value: now}).
+			Limit(c.gcBatchSize).
+			Pluck("key", &keys).Error; err != nil {
+			return err
+		}
+		if len(keys) == 0 {
+			return nil
+		}
+		values := make([]any, len(keys))
+		for i, key := range keys {
+			values[i] = key
+		}
+		// The expire time is checked again, values set after the keys were plucked are kept.
+		if err := c.db.Table(c.tableName).
+			Where(clause.IN{Column: clause.Column{Name: "key"}, Values: values}).
+			Where(clause.Lt{Column: clause.Column{Name: "expire"}, Value: now}).
+			Delete(new(CacheModel)).Error; err != nil {
+			return err
+		}
+		if len(keys) < c.gcBatchSize {
+			return nil
+		}
+	}
+}
+
+func (c *Cache) prepareLease() error {
+	owner := make([]byte, 16)
+	if _, err := rand.Read(owner); err != nil {
+		return err
+	}
+	c.owner = hex.EncodeToString(owner)
+	if c.db.Migrator().HasTable(LeaseTableName) {
+		return nil
+	}
+	if err := c.db.Table(LeaseTableName).Migrator().CreateTable(new(LeaseModel)); err != nil && !c.db.Migrator().HasTable(LeaseTableName) {
+		return err
+	}
+	return nil
+}
+
+// acquireLease takes or renews the gc lease of the table, it reports whether this instance holds the lease.
+func (c *Cache) acquireLease() (bool, error) {
+	now := time.Now()
+	lease := &LeaseModel{
+		Name:      "gc:" + c.tableName,
+		Owner:     c.owner,
+		ExpiresAt: now.Add(c.gcLeaseTTL),
+	}
+	result := c.db.Table(LeaseTableName).Clauses(clause.OnConflict{DoNothing: true}).Create(lease)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return true, nil
+	}
+	result = c.db.Table(LeaseTableName).
+		Where(clause.Eq{Column: clause.Column{Name: "name"}, Value: lease.Name}).
+		Where(clause.Or(
+			clause.Eq{Column: clause.Column{Name: "owner"}, Value: c.owner},
+			clause.Lt{Column: clause.Column{Name: "expires_at"}, Value: now},
+		)).
+		Updates(map[string]any{"owner": lease.Owner, "expires_at": lease.ExpiresAt})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,144 @@
+package database
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func openGCTestDB(t *testing.T, name string) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", name)), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	return db
+}
+
+func TestCache_GC(t *testing.T) {
+	t.Run("batch delete", func(t *testing.T) {
+		db := openGCTestDB(t, "gc_batch")
+		c := New(&Config{
+			DB:          db,
+			GCInterval:  time.Millisecond * 50,
+			GCBatchSize: 2,
+		})
+		defer func() {
+			_ = c.Close()
+		}()
+		for i := 0; i < 5; i++ {
+			assert.NoError(t, c.Set(fmt.Sprintf("key%d", i), "value", time.Millisecond))
+		}
+		assert.NoError(t, c.Set("alive", "value", time.Hour))
+		time.Sleep(time.Millisecond * 200)
+		var count int64
+		assert.NoError(t, db.Table("caches").Count(&count).Error)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		db := openGCTestDB(t, "gc_disabled")
+		enabled := false
+		c := New(&Config{
+			DB:         db,
+			GCEnabled:  &enabled,
+			GCInterval: time.Millisecond * 50,
+		})
+		defer func() {
+			_ = c.Close()
+		}()
+		assert.NoError(t, c.Set("key", "value", time.Millisecond))
+		time.Sleep(time.Millisecond * 200)
+		var count int64
+		assert.NoError(t, db.Table("caches").Count(&count).Error)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("error callback", func(t *testing.T) {
+		db := openGCTestDB(t, "gc_error")
+		var once sync.Once
+		errs := make(chan error, 1)
+		c := New(&Config{
+			DB:         db,
+			GCInterval: time.Millisecond * 50,
+			OnGCError: func(err error) {
+				once.Do(func() {
+					errs <- err
+				})
+			},
+		})
+		defer func() {
+			_ = c.Close()
+		}()
+		assert.NoError(t, db.Migrator().DropTable("caches"))
+		select {
+		case err := <-errs:
+			assert.Error(t, err)
+		case <-time.After(time.Second):
+			assert.FailNow(t, "gc error not reported")
+		}
+	})
+
+	t.Run("lease", func(t *testing.T) {
+		db := openGCTestDB(t, "gc_lease")
+		enabled := false
+		open := func() *Cache {
+			return New(&Config{
+				DB:         db,
+				GCEnabled:  &enabled,
+				GCLease:    true,
+				GCLeaseTTL: time.Millisecond * 100,
+			})
+		}
+		c1, c2 := open(), open()
+		assert.NoError(t, c1.prepareLease())
+		assert.NoError(t, c2.prepareLease())
+		acquired, err := c1.acquireLease()
+		assert.NoError(t, err)
+		assert.True(t, acquired)
+		acquired, err = c2.acquireLease()
+		assert.NoError(t, err)
+		assert.False(t, acquired)
+		acquired, err = c1.acquireLease()
+		assert.NoError(t, err)
+		assert.True(t, acquired)
+		time.Sleep(time.Millisecond * 150)
+		acquired, err = c2.acquireLease()
+		assert.NoError(t, err)
+		assert.True(t, acquired)
+	})
+
+	t.Run("keeps values set after pluck", func(t *testing.T) {
+		db := openGCTestDB(t, "gc_race")
+		enabled := false
+		c := New(&Config{
+			DB:        db,
+			GCEnabled: &enabled,
+		})
+		defer func() {
+			_ = c.Close()
+		}()
+		assert.NoError(t, c.Set("key", "stale", time.Millisecond))
+		time.Sleep(time.Millisecond * 10)
+		// A Set landing between the pluck and the delete of the gc.
+		assert.NoError(t, db.Callback().Delete().Before("gorm:delete").Register("test:set", func(tx *gorm.DB) {
+			tx.AddError(tx.Session(&gorm.Session{NewDB: true}).Table("caches").
+				Where("key = ?", c.buildKey("key")).
+				Updates(map[string]any{"value": "fresh", "expire": time.Now().Add(time.Hour)}).Error)
+		}))
+		defer func() {
+			_ = db.Callback().Delete().Remove("test:set")
+		}()
+		assert.NoError(t, c.collect())
+		value, err := c.Get("key")
+		assert.NoError(t, err)
+		assert.Equal(t, "fresh", value)
+	})
+}
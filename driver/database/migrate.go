@@ -0,0 +1,106 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MigrationTableName is the table which records the schema version of every cache table.
+var MigrationTableName = "cache_migrations"
+
+type migration struct {
+	version int
+	up      func(db *gorm.DB, tableName string) error
+}
+
+// migrations upgrade cache tables created by older versions, version 1 is the original schema
+// with a text value column.
+var migrations = []migration{
+	{version: 2, up: func(db *gorm.DB, tableName string) error {
+		return db.Table(tableName).Migrator().AlterColumn(new(CacheModel), "Value")
+	}},
+	{version: 3, up: func(db *gorm.DB, tableName string) error {
+		return createExpireIndex(db, tableName)
+	}},
+	{version: 4, up: func(db *gorm.DB, tableName string) error {
+		migrator := db.Table(tableName).Migrator()
+		for _, field := range []string{"CreatedAt", "UpdatedAt", "Tags"} {
+			if migrator.HasColumn(new(CacheModel), field) {
+				continue
+			}
+			if err := migrator.AddColumn(new(CacheModel), field); err != nil {
+				return err
+			}
+		}
+		return nil
+	}},
+}
+
+// SchemaVersion is the latest schema version of cache tables.
+var SchemaVersion = migrations[len(migrations)-1].version
+
+// Migrate creates the cache table if it does not exist, or upgrades it to the latest schema version.
+// Instances starting at the same time may race to create the tables, the loser retries once and then
+// finds the tables created by the winner.
+func Migrate(db *gorm.DB, tableName string) error {
+	err := migrate(db, tableName)
+	if err != nil {
+		err = migrate(db, tableName)
+	}
+	return err
+}
+
+func migrate(db *gorm.DB, tableName string) error {
+	if !db.Migrator().HasTable(MigrationTableName) {
+		if err := db.Table(MigrationTableName).Migrator().CreateTable(new(MigrationModel)); err != nil && !db.Migrator().HasTable(MigrationTableName) {
+			return err
+		}
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		var model MigrationModel
+		err := tx.Table(MigrationTableName).Where(clause.Eq{Column: clause.Column{Name: "table_name"}, Value: tableName}).First(&model).Error
+		switch {
+		case err == nil:
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			model.Table = tableName
+			if tx.Migrator().HasTable(tableName) {
+				model.Version = 1
+				break
+			}
+			if err := tx.Table(tableName).Migrator().CreateTable(new(CacheModel)); err != nil {
+				return err
+			}
+			if err := createExpireIndex(tx, tableName); err != nil {
+				return err
+			}
+			model.Version = SchemaVersion
+		default:
+			return err
+		}
+		for _, m := range migrations {
+			if m.version <= model.Version {
+				continue
+			}
+			if err := m.up(tx, tableName); err != nil {
+				return err
+			}
+			model.Version = m.version
+		}
+		model.MigratedAt = time.Now()
+		return tx.Table(MigrationTableName).Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "table_name"}},
+			DoUpdates: clause.AssignmentColumns([]string{"version", "migrated_at"}),
+		}).Create(&model).Error
+	})
+}
+
+func createExpireIndex(db *gorm.DB, tableName string) error {
+	name := "idx_" + tableName + "_expire"
+	if db.Table(tableName).Migrator().HasIndex(new(CacheModel), name) {
+		return nil
+	}
+	return db.Exec("CREATE INDEX ? ON ? (?)", clause.Column{Name: name}, clause.Table{Name: tableName}, clause.Column{Name: "expire"}).Error
+}
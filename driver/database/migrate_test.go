@@ -0,0 +1,100 @@
+package database
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type legacyCacheModel struct {
+	Key    string    `gorm:"column:key;type:varchar(255);not null;primaryKey"`
+	Value  string    `gorm:"column:value;type:text;not null"`
+	Expire time.Time `gorm:"column:expire;type:timestamp;not null"`
+}
+
+func TestMigrate(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:migrate?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+
+	t.Run("create table", func(t *testing.T) {
+		if err := Migrate(db, "new_caches"); err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		var model MigrationModel
+		assert.NoError(t, db.Table(MigrationTableName).First(&model, "table_name = ?", "new_caches").Error)
+		assert.Equal(t, SchemaVersion, model.Version)
+		assert.True(t, db.Table("new_caches").Migrator().HasIndex(new(CacheModel), "idx_new_caches_expire"))
+	})
+
+	t.Run("upgrade legacy table", func(t *testing.T) {
+		if err := db.Table("legacy_caches").Migrator().CreateTable(new(legacyCacheModel)); err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		if err := db.Table("legacy_caches").Create(&legacyCacheModel{
+			Key:    "cache:key",
+			Value:  "value",
+			Expire: time.Now().Add(time.Hour),
+		}).Error; err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		if err := Migrate(db, "legacy_caches"); err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		var model MigrationModel
+		assert.NoError(t, db.Table(MigrationTableName).First(&model, "table_name = ?", "legacy_caches").Error)
+		assert.Equal(t, SchemaVersion, model.Version)
+		migrator := db.Table("legacy_caches").Migrator()
+		assert.True(t, migrator.HasIndex(new(CacheModel), "idx_legacy_caches_expire"))
+		assert.True(t, migrator.HasColumn(new(CacheModel), "CreatedAt"))
+		assert.True(t, migrator.HasColumn(new(CacheModel), "UpdatedAt"))
+		assert.True(t, migrator.HasColumn(new(CacheModel), "Tags"))
+
+		c := New(&Config{DB: db, TableName: "legacy_caches"})
+		value, err := c.Get("key")
+		assert.NoError(t, err)
+		assert.Equal(t, "value", value)
+		assert.NoError(t, c.Set("binary", "\x00\xff\x10", 0))
+		value, err = c.Get("binary")
+		assert.NoError(t, err)
+		assert.Equal(t, "\x00\xff\x10", value)
+	})
+
+	t.Run("migrate twice", func(t *testing.T) {
+		assert.NoError(t, Migrate(db, "legacy_caches"))
+	})
+
+	t.Run("concurrently", func(t *testing.T) {
+		db, err := gorm.Open(sqlite.Open("file:migrate_concurrently?mode=memory&cache=shared"), &gorm.Config{
+			Logger: logger.Default.LogMode(logger.Silent),
+		})
+		if err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		var wg sync.WaitGroup
+		errs := make([]error, 4)
+		for i := range errs {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				errs[i] = Migrate(db, fmt.Sprintf("concurrent_caches_%d", i%2))
+			}(i)
+		}
+		wg.Wait()
+		for _, err := range errs {
+			assert.NoError(t, err)
+		}
+		var count int64
+		assert.NoError(t, db.Table(MigrationTableName).Count(&count).Error)
+		assert.Equal(t, int64(2), count)
+	})
+}
@@ -3,7 +3,30 @@ package database
 import "time"
 
 type CacheModel struct {
-	Key    string    `gorm:"column:key;type:varchar(255);not null;primaryKey;uniqueIndex" json:"key" yaml:"key" toml:"key" mapstructure:"key"`
-	Value  string    `gorm:"column:value;type:text;not null" json:"value" yaml:"value" toml:"value" mapstructure:"value"`
-	Expire time.Time `gorm:"column:expire;type:timestamp;not null" json:"expire" yaml:"expire" toml:"expire" mapstructure:"expire"`
+	Key       string    `gorm:"column:key;type:varchar(255);not null;primaryKey" json:"key" yaml:"key" toml:"key" mapstructure:"key"`
+	Value     []byte    `gorm:"column:value;not null" json:"value" yaml:"value" toml:"value" mapstructure:"value"`
+	Expire    time.Time `gorm:"column:expire;type:timestamp;not null" json:"expire" yaml:"expire" toml:"expire" mapstructure:"expire"`
+	CreatedAt time.Time `gorm:"column:created_at;type:timestamp" json:"created_at" yaml:"created_at" toml:"created_at" mapstructure:"created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at;type:timestamp" json:"updated_at" yaml:"updated_at" toml:"updated_at" mapstructure:"updated_at"`
+	Tags      *string   `gorm:"column:tags;type:varchar(255)" json:"tags" yaml:"tags" toml:"tags" mapstructure:"tags"`
+}
+
+type MigrationModel struct {
+	Table      string    `gorm:"column:table_name;type:varchar(255);not null;primaryKey" json:"table_name" yaml:"table_name" toml:"table_name" mapstructure:"table_name"`
+	Version    int       `gorm:"column:version;not null" json:"version" yaml:"version" toml:"version" mapstructure:"version"`
+	MigratedAt time.Time `gorm:"column:migrated_at;type:timestamp;not null" json:"migrated_at" yaml:"migrated_at" toml:"migrated_at" mapstructure:"migrated_at"`
+}
+
+type LeaseModel struct {
+	Name      string    `gorm:"column:name;type:varchar(255);not null;primaryKey" json:"name" yaml:"name" toml:"name" mapstructure:"name"`
+	Owner     string    `gorm:"column:owner;type:varchar(64);not null" json:"owner" yaml:"owner" toml:"owner" mapstructure:"owner"`
+	ExpiresAt time.Time `gorm:"column:expires_at;type:timestamp;not null" json:"expires_at" yaml:"expires_at" toml:"expires_at" mapstructure:"expires_at"`
+}
+
+type InvalidationModel struct {
+	ID        uint64    `gorm:"column:id;primaryKey;autoIncrement" json:"id" yaml:"id" toml:"id" mapstructure:"id"`
+	Source    string    `gorm:"column:source;type:varchar(64);not null" json:"source" yaml:"source" toml:"source" mapstructure:"source"`
+	Keys      string    `gorm:"column:cache_keys;type:text;not null" json:"cache_keys" yaml:"cache_keys" toml:"cache_keys" mapstructure:"cache_keys"`
+	All       bool      `gorm:"column:cleared;not null" json:"cleared" yaml:"cleared" toml:"cleared" mapstructure:"cleared"`
+	CreatedAt time.Time `gorm:"column:created_at;type:timestamp;not null;index" json:"created_at" yaml:"created_at" toml:"created_at" mapstructure:"created_at"`
 }
@@ -1,11 +1,14 @@
 package file
 
 import (
+	"context"
 	"crypto/md5"
 	"encoding/hex"
+	"errors"
 	"github.com/gopi-frame/cache"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -16,35 +19,22 @@ type Cache struct {
 	storagePath string
 	prefix      string
 	expire      time.Duration
+	sliding     bool
 	dirMode     os.FileMode
 	fileMode    os.FileMode
 }
 
 func New(config *Config) *Cache {
+	config.ApplyDefaults()
 	if config.StoragePath == "" {
-		userCacheDir, err := os.UserCacheDir()
-		if err != nil {
-			return nil
-		}
-		config.StoragePath = filepath.Join(userCacheDir, "gopi-frame")
-	}
-	if config.DirMode == 0 {
-		config.DirMode = 0755
-	}
-	if config.FileMode == 0 {
-		config.FileMode = 0644
-	}
-	if config.Prefix == "" {
-		config.Prefix = "cache"
-	}
-	if config.Expire <= 0 {
-		config.Expire = time.Hour * 72
+		return nil
 	}
 	c := &Cache{
 		mu:          &sync.Mutex{},
 		storagePath: config.StoragePath,
 		prefix:      config.Prefix,
 		expire:      config.Expire,
+		sliding:     config.Sliding,
 		dirMode:     config.DirMode,
 		fileMode:    config.FileMode,
 	}
@@ -62,6 +52,11 @@ func (c *Cache) buildPath(key string) string {
 	return filepath.Join(c.storagePath, c.buildKey(key)+".bin")
 }
 
+// buildTTLPath returns the path of the file holding the expire time key was set with, written in sliding mode.
+func (c *Cache) buildTTLPath(key string) string {
+	return filepath.Join(c.storagePath, c.buildKey(key)+".ttl")
+}
+
 func (c *Cache) gc() {
 	for {
 		files, err := os.ReadDir(c.storagePath)
@@ -69,7 +64,7 @@ func (c *Cache) gc() {
 			return
 		}
 		for _, file := range files {
-			if strings.HasPrefix(file.Name(), c.prefix) && strings.HasSuffix(file.Name(), ".bin") {
+			if strings.HasPrefix(file.Name(), c.prefix) && (strings.HasSuffix(file.Name(), ".bin") || strings.HasSuffix(file.Name(), ".ttl")) {
 				path := filepath.Join(c.storagePath, file.Name())
 				if s, err := os.Stat(path); err != nil {
 					return
@@ -95,9 +90,35 @@ func (c *Cache) Get(key string) (string, error) {
 		_ = os.Remove(path)
 		return "", err
 	}
+	if c.sliding {
+		if err := c.touch(key); err != nil {
+			return "", err
+		}
+	}
 	return string(content), nil
 }
 
+// touch extends the expire time of key by the expire time it was set with.
+// Values set without sliding are extended by the default expire time.
+func (c *Cache) touch(key string) error {
+	expire := c.expire
+	ttlPath := c.buildTTLPath(key)
+	content, err := os.ReadFile(ttlPath)
+	if err == nil {
+		ms, err := strconv.ParseInt(string(content), 10, 64)
+		if err != nil {
+			return err
+		}
+		expire = time.Duration(ms) * time.Millisecond
+		if err := os.Chtimes(ttlPath, time.Now(), time.Now().Add(expire)); err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	return os.Chtimes(c.buildPath(key), time.Now(), time.Now().Add(expire))
+}
+
 func (c *Cache) Set(key string, value string, expire time.Duration) error {
 	path := c.buildPath(key)
 	if err := os.MkdirAll(filepath.Dir(path), c.dirMode); err != nil {
@@ -116,6 +137,15 @@ func (c *Cache) Set(key string, value string, expire time.Duration) error {
 	if expire <= 0 {
 		expire = c.expire
 	}
+	if c.sliding {
+		ttlPath := c.buildTTLPath(key)
+		if err := os.WriteFile(ttlPath, []byte(strconv.FormatInt(expire.Milliseconds(), 10)), c.fileMode); err != nil {
+			return err
+		}
+		if err := os.Chtimes(ttlPath, time.Now(), time.Now().Add(expire)); err != nil {
+			return err
+		}
+	}
 	return os.Chtimes(path, time.Now(), time.Now().Add(expire))
 }
 
@@ -134,6 +164,11 @@ func (c *Cache) Load(key string, loader func() (string, error), expire time.Dura
 }
 
 func (c *Cache) Delete(key string) error {
+	if c.sliding {
+		if err := os.Remove(c.buildTTLPath(key)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
 	if err := os.Remove(c.buildPath(key)); err != nil {
 		if os.IsNotExist(err) {
 			return nil
@@ -173,3 +208,37 @@ func (c *Cache) Clear() error {
 	}
 	return nil
 }
+
+// CheckHealth writes, reads back and removes a probe file in the storage directory.
+func (c *Cache) CheckHealth(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.storagePath, c.dirMode); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(c.storagePath, ".health-*")
+	if err != nil {
+		return err
+	}
+	path := f.Name()
+	defer func() {
+		_ = os.Remove(path)
+	}()
+	probe := strconv.FormatInt(time.Now().UnixNano(), 10)
+	_, err = f.WriteString(probe)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if string(content) != probe {
+		return errors.New("health probe mismatch")
+	}
+	return nil
+}
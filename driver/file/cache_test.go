@@ -1,9 +1,12 @@
 package file
 
 import (
+	"context"
 	"github.com/gopi-frame/cache"
 	cc "github.com/gopi-frame/contract/cache"
 	"github.com/stretchr/testify/assert"
+	"os"
+	"strings"
 	"testing"
 	"time"
 )
@@ -145,3 +148,66 @@ func TestCache_Clear(t *testing.T) {
 	assert.False(t, testCache.Has("key"))
 	assert.False(t, testCache.Has("key2"))
 }
+
+func TestCache_CheckHealth(t *testing.T) {
+	checker, ok := testCache.(cache.HealthChecker)
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.NoError(t, checker.CheckHealth(context.Background()))
+
+	entries, err := os.ReadDir(testCache.(*Cache).storagePath)
+	assert.NoError(t, err)
+	for _, entry := range entries {
+		assert.False(t, strings.HasPrefix(entry.Name(), ".health-"), "probe file %s is left", entry.Name())
+	}
+}
+
+func TestCache_Encryption(t *testing.T) {
+	keyring, err := cache.NewKeyring("v1", map[string][]byte{"v1": []byte("0123456789abcdef0123456789abcdef")})
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	c := cache.NewEncryptionCache(testCache, keyring)
+	assert.NoError(t, c.Set("encrypted", "secret", time.Minute))
+	raw, err := os.ReadFile(testCache.(*Cache).buildPath("encrypted"))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(raw), "secret")
+	value, err := c.Get("encrypted")
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", value)
+}
+
+func TestCache_Sliding(t *testing.T) {
+	dir := t.TempDir()
+	c := New(&Config{StoragePath: dir, Expire: time.Minute, Sliding: true})
+	if err := c.Set("key", "value", time.Hour); err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	if err := os.Chtimes(c.buildPath("key"), time.Now(), time.Now().Add(time.Second)); err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	value, err := c.Get("key")
+	assert.NoError(t, err)
+	assert.Equal(t, "value", value)
+	s, err := os.Stat(c.buildPath("key"))
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	assert.WithinDuration(t, time.Now().Add(time.Hour), s.ModTime(), time.Second)
+
+	if err := New(&Config{StoragePath: dir}).Set("legacy", "value", time.Second); err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	_, err = c.Get("legacy")
+	assert.NoError(t, err)
+	s, err = os.Stat(c.buildPath("legacy"))
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	assert.WithinDuration(t, time.Now().Add(time.Minute), s.ModTime(), time.Second)
+
+	assert.NoError(t, c.Delete("key"))
+	_, err = os.Stat(c.buildTTLPath("key"))
+	assert.True(t, os.IsNotExist(err))
+}
@@ -2,6 +2,7 @@ package file
 
 import (
 	"os"
+	"path/filepath"
 	"time"
 )
 
@@ -16,6 +17,9 @@ type Config struct {
 	// Expire is the default expiration time for the cache files.
 	// If not set, the default is 72 hours.
 	Expire time.Duration `json:"expire" yaml:"expire" toml:"expire" mapstructure:"expire"`
+	// Sliding makes Get extend the expire time of values by the expire time they were set with.
+	// The expire time is stored in a ".ttl" file next to the value.
+	Sliding bool `json:"sliding" yaml:"sliding" toml:"sliding" mapstructure:"sliding"`
 	// DirMode is the mode for the cache directory.
 	// If not set, the default is 0755.
 	DirMode os.FileMode `json:"dirMode" yaml:"dirMode" toml:"dirMode" mapstructure:"dirMode"`
@@ -23,3 +27,24 @@ type Config struct {
 	// If not set, the default is 0644.
 	FileMode os.FileMode `json:"fileMode" yaml:"fileMode" toml:"fileMode" mapstructure:"fileMode"`
 }
+
+// ApplyDefaults sets the default values of unset fields.
+func (c *Config) ApplyDefaults() {
+	if c.StoragePath == "" {
+		if userCacheDir, err := os.UserCacheDir(); err == nil {
+			c.StoragePath = filepath.Join(userCacheDir, "gopi-frame")
+		}
+	}
+	if c.DirMode == 0 {
+		c.DirMode = 0755
+	}
+	if c.FileMode == 0 {
+		c.FileMode = 0644
+	}
+	if c.Prefix == "" {
+		c.Prefix = "cache"
+	}
+	if c.Expire <= 0 {
+		c.Expire = time.Hour * 72
+	}
+}
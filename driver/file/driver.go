@@ -1,8 +1,8 @@
 package file
 
 import (
-	"github.com/go-viper/mapstructure/v2"
 	"github.com/gopi-frame/cache"
+	"github.com/gopi-frame/exception"
 )
 import cc "github.com/gopi-frame/contract/cache"
 
@@ -20,10 +20,13 @@ type Driver struct{}
 
 func (d *Driver) Open(config map[string]any) (cc.Cache, error) {
 	var cfg Config
-	err := mapstructure.Decode(config, &cfg)
+	err := cache.DecodeConfig(config, &cfg)
 	if err != nil {
 		return nil, err
 	}
+	if cfg.StoragePath == "" {
+		return nil, exception.NewArgumentException("storagePath", cfg.StoragePath, "storage path is required")
+	}
 	c := New(&cfg)
 
 	return c, nil
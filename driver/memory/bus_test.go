@@ -0,0 +1,76 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gopi-frame/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+type localBus struct {
+	mu       sync.Mutex
+	handlers []func(message cache.Invalidation)
+}
+
+func (b *localBus) Publish(_ context.Context, message cache.Invalidation) error {
+	b.mu.Lock()
+	handlers := append([]func(message cache.Invalidation){}, b.handlers...)
+	b.mu.Unlock()
+	for _, handler := range handlers {
+		handler(message)
+	}
+	return nil
+}
+
+func (b *localBus) Subscribe(_ context.Context, handler func(message cache.Invalidation)) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, handler)
+	return nil
+}
+
+func (b *localBus) Close() error {
+	return nil
+}
+
+func TestOpen_WithBus(t *testing.T) {
+	bus := new(localBus)
+	c1, err := Open(map[string]any{"expire": time.Minute, "bus": bus})
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	c2, err := Open(map[string]any{"expire": time.Minute, "bus": bus})
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+
+	t.Run("delete", func(t *testing.T) {
+		assert.NoError(t, c1.Set("key", "value", 0))
+		assert.NoError(t, c2.Set("key", "value", 0))
+		assert.NoError(t, c1.Delete("key"))
+		assert.False(t, c1.Has("key"))
+		assert.False(t, c2.Has("key"))
+	})
+
+	t.Run("clear", func(t *testing.T) {
+		assert.NoError(t, c1.Set("key", "value", 0))
+		assert.NoError(t, c2.Set("key", "value", 0))
+		assert.NoError(t, c2.Clear())
+		assert.False(t, c1.Has("key"))
+		assert.False(t, c2.Has("key"))
+	})
+
+	t.Run("ignore own messages", func(t *testing.T) {
+		assert.NoError(t, c1.Set("key", "value", 0))
+		assert.NoError(t, c2.Set("key", "value", 0))
+		assert.NoError(t, bus.Publish(context.Background(), cache.Invalidation{
+			Source: c1.(*cache.InvalidatingCache).ID(),
+			Keys:   []string{"key"},
+		}))
+		assert.True(t, c1.Has("key"))
+		assert.False(t, c2.Has("key"))
+	})
+}
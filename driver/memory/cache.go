@@ -1,44 +1,100 @@
 package memory
 
 import (
+	"context"
 	"github.com/gopi-frame/cache"
 	"sync"
 	"time"
 )
 
 type Cache struct {
-	expire time.Duration
-	data   map[string]struct {
+	expire  time.Duration
+	sliding bool
+	data    map[string]struct {
 		value  string
 		expire time.Time
+		ttl    time.Duration
 	}
-	mu *sync.RWMutex
+	mu      *sync.RWMutex
+	onEvict []func(key string)
 }
 
 func New(expire time.Duration) *Cache {
-	if expire <= 0 {
-		expire = time.Hour * 72
-	}
+	return NewWithConfig(&Config{Expire: expire})
+}
+
+// NewWithConfig creates a cache from config, the bus is used by [Driver.Open] only.
+func NewWithConfig(config *Config) *Cache {
+	config.ApplyDefaults()
 	return &Cache{
-		expire: expire,
+		expire:  config.Expire,
+		sliding: config.Sliding,
 		data: make(map[string]struct {
 			value  string
 			expire time.Time
+			ttl    time.Duration
 		}),
 		mu: &sync.RWMutex{},
 	}
 }
 
 func (c *Cache) Get(key string) (string, error) {
+	if c.sliding {
+		return c.touch(key)
+	}
 	c.mu.RLock()
-	defer c.mu.RUnlock()
-	if v, ok := c.data[key]; ok {
-		if v.expire.After(time.Now()) {
-			return v.value, nil
-		}
-		delete(c.data, key)
-	}
-	return "", cache.ErrCacheNotFound
+	v, ok := c.data[key]
+	c.mu.RUnlock()
+	if !ok {
+		return "", cache.ErrCacheNotFound
+	}
+	if !v.expire.After(time.Now()) {
+		c.evict(key)
+		return "", cache.ErrCacheNotFound
+	}
+	return v.value, nil
+}
+
+// OnEvict registers fn to be called with the key of every expired value removed by the cache.
+func (c *Cache) OnEvict(fn func(key string)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onEvict = append(c.onEvict, fn)
+}
+
+// evict removes key if it has expired, the value may have been set again since it was found expired.
+func (c *Cache) evict(key string) {
+	c.mu.Lock()
+	v, ok := c.data[key]
+	if !ok || v.expire.After(time.Now()) {
+		c.mu.Unlock()
+		return
+	}
+	delete(c.data, key)
+	onEvict := c.onEvict
+	c.mu.Unlock()
+	for _, fn := range onEvict {
+		fn(key)
+	}
+}
+
+// touch gets the value of key and extends its expire time by the expire time it was set with.
+func (c *Cache) touch(key string) (string, error) {
+	c.mu.Lock()
+	v, ok := c.data[key]
+	if !ok {
+		c.mu.Unlock()
+		return "", cache.ErrCacheNotFound
+	}
+	if !v.expire.After(time.Now()) {
+		c.mu.Unlock()
+		c.evict(key)
+		return "", cache.ErrCacheNotFound
+	}
+	v.expire = time.Now().Add(v.ttl)
+	c.data[key] = v
+	c.mu.Unlock()
+	return v.value, nil
 }
 
 func (c *Cache) Set(key string, value string, expire time.Duration) error {
@@ -50,9 +106,11 @@ func (c *Cache) Set(key string, value string, expire time.Duration) error {
 	c.data[key] = struct {
 		value  string
 		expire time.Time
+		ttl    time.Duration
 	}{
 		value:  value,
 		expire: time.Now().Add(expire),
+		ttl:    expire,
 	}
 	return nil
 }
@@ -73,14 +131,16 @@ func (c *Cache) Load(key string, loader func() (string, error), expire time.Dura
 
 func (c *Cache) Has(key string) bool {
 	c.mu.RLock()
-	defer c.mu.RUnlock()
-	if v, ok := c.data[key]; ok {
-		if v.expire.After(time.Now()) {
-			return true
-		}
-		delete(c.data, key)
-	}
-	return false
+	v, ok := c.data[key]
+	c.mu.RUnlock()
+	if !ok {
+		return false
+	}
+	if !v.expire.After(time.Now()) {
+		c.evict(key)
+		return false
+	}
+	return true
 }
 
 func (c *Cache) Delete(key string) error {
@@ -96,6 +156,12 @@ func (c *Cache) Clear() error {
 	c.data = make(map[string]struct {
 		value  string
 		expire time.Time
+		ttl    time.Duration
 	})
 	return nil
 }
+
+// CheckHealth always succeeds as the memory cache has no backend.
+func (c *Cache) CheckHealth(_ context.Context) error {
+	return nil
+}
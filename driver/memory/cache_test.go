@@ -1,6 +1,7 @@
 package memory
 
 import (
+	"context"
 	"github.com/gopi-frame/cache"
 	cc "github.com/gopi-frame/contract/cache"
 	"github.com/stretchr/testify/assert"
@@ -144,3 +145,86 @@ func TestCache_Clear(t *testing.T) {
 	assert.False(t, testCache.Has("key"))
 	assert.False(t, testCache.Has("key2"))
 }
+
+func TestOpen(t *testing.T) {
+	t.Run("string expire", func(t *testing.T) {
+		c, err := Open(map[string]any{"expire": "1h"})
+		if err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		assert.Equal(t, time.Hour, c.(*Cache).expire)
+	})
+
+	t.Run("default expire", func(t *testing.T) {
+		c, err := Open(map[string]any{})
+		if err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		assert.Equal(t, time.Hour*72, c.(*Cache).expire)
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		_, err := Open(map[string]any{"expires": "1h", "strict": true})
+		assert.Error(t, err)
+	})
+}
+
+func TestCache_CheckHealth(t *testing.T) {
+	checker, ok := testCache.(cache.HealthChecker)
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.NoError(t, checker.CheckHealth(context.Background()))
+}
+
+func TestCache_Sliding(t *testing.T) {
+	c := NewWithConfig(&Config{Expire: time.Minute, Sliding: true})
+	if err := c.Set("key", "value", time.Hour); err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	setExpire := func(expire time.Time) {
+		v := c.data["key"]
+		v.expire = expire
+		c.data["key"] = v
+	}
+	setExpire(time.Now().Add(time.Second))
+	value, err := c.Get("key")
+	assert.NoError(t, err)
+	assert.Equal(t, "value", value)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), c.data["key"].expire, time.Second)
+
+	setExpire(time.Now().Add(-time.Second))
+	_, err = c.Get("key")
+	assert.ErrorIs(t, err, cache.ErrCacheNotFound)
+
+	opened, err := Open(map[string]any{"sliding": true})
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	assert.True(t, opened.(*Cache).sliding)
+}
+
+func TestCache_OnEvict(t *testing.T) {
+	c := New(time.Minute)
+	var evicted []string
+	c.OnEvict(func(key string) {
+		evicted = append(evicted, key)
+	})
+	assert.NoError(t, c.Set("expired", "value", time.Millisecond))
+	assert.NoError(t, c.Set("alive", "value", time.Hour))
+	assert.NoError(t, c.Set("deleted", "value", time.Hour))
+	time.Sleep(time.Millisecond * 5)
+	assert.False(t, c.Has("expired"))
+	_, err := c.Get("expired")
+	assert.ErrorIs(t, err, cache.ErrCacheNotFound)
+	assert.True(t, c.Has("alive"))
+	assert.NoError(t, c.Delete("deleted"))
+	assert.Equal(t, []string{"expired"}, evicted)
+
+	metrics := cache.NewMetrics()
+	store := metrics.Wrap("memory", c)
+	assert.NoError(t, store.Set("expired", "value", time.Millisecond))
+	time.Sleep(time.Millisecond * 5)
+	_, _ = store.Get("expired")
+	assert.Equal(t, uint64(1), metrics.Stats()[0].Evictions)
+}
@@ -0,0 +1,23 @@
+package memory
+
+import (
+	"github.com/gopi-frame/cache"
+	"time"
+)
+
+// Config is the memory cache config.
+type Config struct {
+	// Expire is the default cache expire time, default is 72 hour.
+	Expire time.Duration `json:"expire" yaml:"expire" toml:"expire" mapstructure:"expire"`
+	// Sliding makes Get extend the expire time of values by the expire time they were set with.
+	Sliding bool `json:"sliding" yaml:"sliding" toml:"sliding" mapstructure:"sliding"`
+	// Bus is the invalidation bus shared with the caches of other instances.
+	Bus cache.InvalidationBus `json:"-" yaml:"-" toml:"-" mapstructure:"bus"`
+}
+
+// ApplyDefaults sets the default values of unset fields.
+func (c *Config) ApplyDefaults() {
+	if c.Expire <= 0 {
+		c.Expire = time.Hour * 72
+	}
+}
@@ -3,7 +3,6 @@ package memory
 import (
 	"github.com/gopi-frame/cache"
 	cc "github.com/gopi-frame/contract/cache"
-	"time"
 )
 
 // This variable can be replaced through `go build -ldflags=-X github.com/gopi-frame/cache/driver/memory.driverName=custom`
@@ -19,7 +18,15 @@ func init() {
 type Driver struct{}
 
 func (d *Driver) Open(config map[string]any) (cc.Cache, error) {
-	return New(config["expire"].(time.Duration)), nil
+	var cfg Config
+	if err := cache.DecodeConfig(config, &cfg); err != nil {
+		return nil, err
+	}
+	c := NewWithConfig(&cfg)
+	if cfg.Bus != nil {
+		return cache.NewInvalidatingCache(c, cfg.Bus)
+	}
+	return c, nil
 }
 
 func Open(config map[string]any) (cc.Cache, error) {
@@ -0,0 +1,83 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/gopi-frame/cache"
+	"github.com/gopi-frame/contract/redis"
+	"github.com/gopi-frame/exception"
+)
+
+// Bus is an invalidation bus based on redis pub/sub.
+type Bus struct {
+	client  redis.Client
+	channel string
+
+	once sync.Once
+	done chan struct{}
+}
+
+// NewBus creates a new invalidation bus publishing to channel, default is "cache:invalidation".
+func NewBus(client redis.Client, channel string) *Bus {
+	if client == nil {
+		panic(exception.NewEmptyArgumentException("client"))
+	}
+	if channel == "" {
+		channel = "cache:invalidation"
+	}
+	return &Bus{
+		client:  client,
+		channel: channel,
+		done:    make(chan struct{}),
+	}
+}
+
+func (b *Bus) Publish(ctx context.Context, message cache.Invalidation) error {
+	bs, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	return b.client.Publish(ctx, b.channel, bs).Err()
+}
+
+func (b *Bus) Subscribe(ctx context.Context, handler func(message cache.Invalidation)) error {
+	pubsub := b.client.Subscribe(ctx, b.channel)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return err
+	}
+	go func() {
+		defer func() {
+			_ = pubsub.Close()
+		}()
+		ch := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-b.done:
+				return
+			case msg, ok := <-ch:
+				if !ok {
+					return
+				}
+				var message cache.Invalidation
+				if err := json.Unmarshal([]byte(msg.Payload), &message); err != nil {
+					continue
+				}
+				handler(message)
+			}
+		}
+	}()
+	return nil
+}
+
+// Close stops all subscriptions, the client is left open.
+func (b *Bus) Close() error {
+	b.once.Do(func() {
+		close(b.done)
+	})
+	return nil
+}
@@ -0,0 +1,39 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gopi-frame/cache"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBus(t *testing.T) {
+	bus := NewBus(redis.NewClient(&redis.Options{
+		Addr: "localhost:6379",
+	}), "")
+	defer func() {
+		_ = bus.Close()
+	}()
+	received := make(chan cache.Invalidation, 1)
+	if err := bus.Subscribe(context.Background(), func(message cache.Invalidation) {
+		received <- message
+	}); err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	if err := bus.Publish(context.Background(), cache.Invalidation{
+		Source: "peer",
+		All:    true,
+	}); err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	select {
+	case message := <-received:
+		assert.Equal(t, "peer", message.Source)
+		assert.True(t, message.All)
+	case <-time.After(time.Second):
+		assert.FailNow(t, "invalidation not received")
+	}
+}
@@ -2,15 +2,19 @@ package redis
 
 import (
 	"context"
+	"errors"
 	"github.com/gopi-frame/cache"
 	"github.com/gopi-frame/contract/redis"
+	goredis "github.com/redis/go-redis/v9"
 	"time"
 )
 
 type Cache struct {
-	client redis.Client
-	prefix string
-	expire time.Duration
+	client  redis.Client
+	owned   bool
+	prefix  string
+	expire  time.Duration
+	sliding bool
 }
 
 // New creates a new cache.
@@ -18,24 +22,63 @@ func New(config *Config) *Cache {
 	if config.Client == nil {
 		panic("client is required")
 	}
-	if config.Prefix == "" {
-		config.Prefix = "cache"
-	}
-	if config.Expire <= 0 {
-		config.Expire = time.Hour * 72
-	}
+	config.ApplyDefaults()
 	return &Cache{
-		client: config.Client,
-		prefix: config.Prefix,
-		expire: config.Expire,
+		client:  config.Client,
+		prefix:  config.Prefix,
+		expire:  config.Expire,
+		sliding: config.Sliding,
+	}
+}
+
+// Close closes the client if it was created by the cache from the config.
+func (c *Cache) Close() error {
+	if !c.owned {
+		return nil
 	}
+	return c.client.Close()
+}
+
+// CheckHealth pings the redis server.
+func (c *Cache) CheckHealth(ctx context.Context) error {
+	return c.client.Ping(ctx).Err()
 }
 
 func (c *Cache) buildKey(key string) string {
 	return c.prefix + ":" + key
 }
 
+// buildTTLKey returns the key of the expire time key was set with, stored in sliding mode.
+func (c *Cache) buildTTLKey(key string) string {
+	return c.buildKey(key) + "\x00ttl"
+}
+
+// touch gets the value of key and extends its expire time by the expire time it was set with.
+// Values set without sliding are extended by the default expire time.
+func (c *Cache) touch(key string) (string, error) {
+	expire := c.expire
+	ms, err := c.client.Get(context.Background(), c.buildTTLKey(key)).Int64()
+	if err == nil {
+		expire = time.Duration(ms) * time.Millisecond
+	} else if !errors.Is(err, goredis.Nil) {
+		return "", err
+	}
+	pipe := c.client.TxPipeline()
+	value := pipe.GetEx(context.Background(), c.buildKey(key), expire)
+	pipe.PExpire(context.Background(), c.buildTTLKey(key), expire)
+	if _, err := pipe.Exec(context.Background()); err != nil && !errors.Is(err, goredis.Nil) {
+		return "", err
+	}
+	if errors.Is(value.Err(), goredis.Nil) {
+		return "", cache.ErrCacheNotFound
+	}
+	return value.Result()
+}
+
 func (c *Cache) Get(key string) (string, error) {
+	if c.sliding {
+		return c.touch(key)
+	}
 	if !c.Has(key) {
 		return "", cache.ErrCacheNotFound
 	}
@@ -46,6 +89,13 @@ func (c *Cache) Set(key string, value string, expire time.Duration) error {
 	if expire <= 0 {
 		expire = c.expire
 	}
+	if c.sliding {
+		pipe := c.client.TxPipeline()
+		pipe.Set(context.Background(), c.buildKey(key), value, expire)
+		pipe.Set(context.Background(), c.buildTTLKey(key), expire.Milliseconds(), expire)
+		_, err := pipe.Exec(context.Background())
+		return err
+	}
 	return c.client.Set(context.Background(), c.buildKey(key), value, expire).Err()
 }
 
@@ -65,6 +115,9 @@ func (c *Cache) Load(key string, loader func() (value string, err error), expire
 }
 
 func (c *Cache) Delete(key string) error {
+	if c.sliding {
+		return c.client.Del(context.Background(), c.buildKey(key), c.buildTTLKey(key)).Err()
+	}
 	return c.client.Del(context.Background(), c.buildKey(key)).Err()
 }
 
@@ -81,3 +134,51 @@ func (c *Cache) Clear() error {
 	}
 	return iter.Err()
 }
+
+// HGet gets a field of the hash stored at key.
+func (c *Cache) HGet(key string, field string) (string, error) {
+	v, err := c.client.HGet(context.Background(), c.buildKey(key), field).Result()
+	if errors.Is(err, goredis.Nil) {
+		return "", cache.ErrCacheNotFound
+	}
+	return v, err
+}
+
+// HSet sets fields of the hash stored at key and resets the expire time of the whole key.
+func (c *Cache) HSet(key string, values map[string]string, expire time.Duration) error {
+	if len(values) == 0 {
+		return nil
+	}
+	if expire <= 0 {
+		expire = c.expire
+	}
+	args := make([]any, 0, len(values)*2)
+	for field, value := range values {
+		args = append(args, field, value)
+	}
+	pipe := c.client.TxPipeline()
+	pipe.HSet(context.Background(), c.buildKey(key), args...)
+	pipe.Expire(context.Background(), c.buildKey(key), expire)
+	_, err := pipe.Exec(context.Background())
+	return err
+}
+
+// HGetAll gets all fields of the hash stored at key.
+func (c *Cache) HGetAll(key string) (map[string]string, error) {
+	values, err := c.client.HGetAll(context.Background(), c.buildKey(key)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(values) == 0 {
+		return nil, cache.ErrCacheNotFound
+	}
+	return values, nil
+}
+
+// HDel deletes fields of the hash stored at key.
+func (c *Cache) HDel(key string, fields ...string) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	return c.client.HDel(context.Background(), c.buildKey(key), fields...).Err()
+}
@@ -1,6 +1,7 @@
 package redis
 
 import (
+	"context"
 	"github.com/gopi-frame/cache"
 	cc "github.com/gopi-frame/contract/cache"
 	"github.com/redis/go-redis/v9"
@@ -151,3 +152,117 @@ func TestCache_Clear(t *testing.T) {
 	assert.False(t, testCache.Has("key"))
 	assert.False(t, testCache.Has("key2"))
 }
+
+func TestCache_Hash(t *testing.T) {
+	type user struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+		Age   int    `cache:"age"`
+		Token string `cache:"-"`
+	}
+	c, err := cache.New[user](testCache)
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	h, err := c.Hash()
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+
+	t.Run("hash not exist", func(t *testing.T) {
+		_, err := h.Get("user")
+		assert.ErrorIs(t, err, cache.ErrCacheNotFound)
+	})
+
+	t.Run("set and get", func(t *testing.T) {
+		if err := h.Set("user", user{Name: "gopi", Email: "gopi@example.com", Age: 18, Token: "secret"}, 0); err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		v, err := h.Get("user")
+		assert.NoError(t, err)
+		assert.Equal(t, user{Name: "gopi", Email: "gopi@example.com", Age: 18}, v)
+	})
+
+	t.Run("set fields", func(t *testing.T) {
+		if err := h.SetFields("user", user{Name: "other", Age: 20}, 0, "age"); err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		v, err := h.Get("user")
+		assert.NoError(t, err)
+		assert.Equal(t, user{Name: "gopi", Email: "gopi@example.com", Age: 20}, v)
+		assert.Error(t, h.SetFields("user", user{}, 0, "unknown"))
+	})
+
+	t.Run("delete fields", func(t *testing.T) {
+		if err := h.DeleteFields("user", "email"); err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		v, err := h.Get("user")
+		assert.NoError(t, err)
+		assert.Equal(t, user{Name: "gopi", Age: 20}, v)
+	})
+
+	t.Run("expire", func(t *testing.T) {
+		if err := h.Set("user", user{Name: "gopi"}, time.Second); err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		time.Sleep(time.Second * 2)
+		_, err := h.Get("user")
+		assert.ErrorIs(t, err, cache.ErrCacheNotFound)
+	})
+}
+
+func TestCache_CheckHealth(t *testing.T) {
+	checker, ok := testCache.(cache.HealthChecker)
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.NoError(t, checker.CheckHealth(context.Background()))
+
+	t.Run("unreachable", func(t *testing.T) {
+		c := New(&Config{
+			Client: redis.NewClient(&redis.Options{
+				Addr:        "localhost:1",
+				DialTimeout: 100 * time.Millisecond,
+				MaxRetries:  -1,
+			}),
+		})
+		defer func() {
+			_ = c.client.Close()
+		}()
+		assert.Error(t, c.CheckHealth(context.Background()))
+	})
+}
+
+func TestCache_Sliding(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	defer func() {
+		_ = client.Close()
+	}()
+	c := New(&Config{Client: client, Prefix: "sliding", Expire: time.Minute, Sliding: true})
+	if err := c.Set("key", "value", time.Hour); err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	assert.NoError(t, client.PExpire(context.Background(), "sliding:key", time.Second).Err())
+	value, err := c.Get("key")
+	assert.NoError(t, err)
+	assert.Equal(t, "value", value)
+	ttl, err := client.TTL(context.Background(), "sliding:key").Result()
+	assert.NoError(t, err)
+	assert.Greater(t, ttl, 59*time.Minute)
+	assert.LessOrEqual(t, ttl, time.Hour)
+
+	assert.NoError(t, client.Set(context.Background(), "sliding:legacy", "value", time.Second).Err())
+	value, err = c.Get("legacy")
+	assert.NoError(t, err)
+	assert.Equal(t, "value", value)
+	ttl, err = client.TTL(context.Background(), "sliding:legacy").Result()
+	assert.NoError(t, err)
+	assert.Greater(t, ttl, 59*time.Second)
+	assert.LessOrEqual(t, ttl, time.Minute)
+
+	assert.NoError(t, c.Delete("key"))
+	assert.Zero(t, client.Exists(context.Background(), "sliding:key", "sliding:key\x00ttl").Val())
+	_, err = c.Get("missing")
+	assert.ErrorIs(t, err, cache.ErrCacheNotFound)
+}
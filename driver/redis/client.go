@@ -0,0 +1,141 @@
+package redis
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"time"
+
+	"github.com/gopi-frame/contract/redis"
+	"github.com/gopi-frame/exception"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// NewClient creates a redis client from the connection settings of config.
+func NewClient(config *Config) (redis.Client, error) {
+	tlsConfig, err := config.TLS.build()
+	if err != nil {
+		return nil, err
+	}
+	if config.URL != "" {
+		return newClientFromURL(config, tlsConfig)
+	}
+	opts := &goredis.UniversalOptions{
+		Addrs:        config.Addrs,
+		Username:     config.Username,
+		Password:     config.Password,
+		DB:           config.DB,
+		PoolSize:     config.PoolSize,
+		MinIdleConns: config.MinIdleConns,
+		DialTimeout:  config.DialTimeout,
+		ReadTimeout:  config.ReadTimeout,
+		WriteTimeout: config.WriteTimeout,
+		TLSConfig:    tlsConfig,
+	}
+	switch {
+	case config.Sentinel != nil:
+		if config.Sentinel.MasterName == "" {
+			return nil, exception.NewEmptyArgumentException("sentinel.master_name")
+		}
+		opts.MasterName = config.Sentinel.MasterName
+		opts.SentinelUsername = config.Sentinel.Username
+		opts.SentinelPassword = config.Sentinel.Password
+		if len(config.Sentinel.Addrs) > 0 {
+			opts.Addrs = config.Sentinel.Addrs
+		}
+		if len(opts.Addrs) == 0 {
+			return nil, exception.NewEmptyArgumentException("sentinel.addrs")
+		}
+		return goredis.NewFailoverClient(opts.Failover()), nil
+	case config.Cluster != nil:
+		if len(config.Cluster.Addrs) > 0 {
+			opts.Addrs = config.Cluster.Addrs
+		}
+		if len(opts.Addrs) == 0 {
+			return nil, exception.NewEmptyArgumentException("cluster.addrs")
+		}
+		opts.ReadOnly = config.Cluster.ReadOnly
+		opts.RouteByLatency = config.Cluster.RouteByLatency
+		opts.RouteRandomly = config.Cluster.RouteRandomly
+		return goredis.NewClusterClient(opts.Cluster()), nil
+	case len(opts.Addrs) > 0:
+		return goredis.NewClient(opts.Simple()), nil
+	default:
+		return nil, exception.NewArgumentException("client", config.Client, "client or connection settings are required")
+	}
+}
+
+func newClientFromURL(config *Config, tlsConfig *tls.Config) (redis.Client, error) {
+	if config.Cluster != nil {
+		opts, err := goredis.ParseClusterURL(config.URL)
+		if err != nil {
+			return nil, err
+		}
+		opts.ReadOnly = config.Cluster.ReadOnly
+		opts.RouteByLatency = config.Cluster.RouteByLatency
+		opts.RouteRandomly = config.Cluster.RouteRandomly
+		config.override(&opts.PoolSize, &opts.MinIdleConns, &opts.DialTimeout, &opts.ReadTimeout, &opts.WriteTimeout)
+		if tlsConfig != nil {
+			opts.TLSConfig = tlsConfig
+		}
+		return goredis.NewClusterClient(opts), nil
+	}
+	opts, err := goredis.ParseURL(config.URL)
+	if err != nil {
+		return nil, err
+	}
+	config.override(&opts.PoolSize, &opts.MinIdleConns, &opts.DialTimeout, &opts.ReadTimeout, &opts.WriteTimeout)
+	if tlsConfig != nil {
+		opts.TLSConfig = tlsConfig
+	}
+	return goredis.NewClient(opts), nil
+}
+
+// override replaces the options parsed from the url with the ones set explicitly.
+func (c *Config) override(poolSize, minIdleConns *int, dialTimeout, readTimeout, writeTimeout *time.Duration) {
+	if c.PoolSize > 0 {
+		*poolSize = c.PoolSize
+	}
+	if c.MinIdleConns > 0 {
+		*minIdleConns = c.MinIdleConns
+	}
+	if c.DialTimeout > 0 {
+		*dialTimeout = c.DialTimeout
+	}
+	if c.ReadTimeout > 0 {
+		*readTimeout = c.ReadTimeout
+	}
+	if c.WriteTimeout > 0 {
+		*writeTimeout = c.WriteTimeout
+	}
+}
+
+func (c *TLSConfig) build() (*tls.Config, error) {
+	if c == nil {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         c.ServerName,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, exception.NewArgumentException("tls.ca_file", c.CAFile, "no certificates found")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if c.CertFile != "" || c.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
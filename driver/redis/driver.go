@@ -1,10 +1,8 @@
 package redis
 
 import (
-	"github.com/go-viper/mapstructure/v2"
 	"github.com/gopi-frame/cache"
 	cc "github.com/gopi-frame/contract/cache"
-	"github.com/gopi-frame/exception"
 )
 
 // This variable can be replaced through `go build -ldflags=-X github.com/gopi-frame/cache/driver/redis.driverName=custom`
@@ -21,14 +19,20 @@ type Driver struct{}
 
 func (d *Driver) Open(config map[string]any) (cc.Cache, error) {
 	var cfg Config
-	err := mapstructure.WeakDecode(config, &cfg)
-	if err != nil {
+	if err := cache.DecodeConfig(config, &cfg); err != nil {
 		return nil, err
 	}
+	var owned bool
 	if cfg.Client == nil {
-		return nil, exception.NewArgumentException("client", cfg.Client, "client is required")
+		client, err := NewClient(&cfg)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Client = client
+		owned = true
 	}
 	c := New(&cfg)
+	c.owned = owned
 	return c, nil
 }
 
@@ -1,6 +1,7 @@
 package redis
 
 import (
+	"context"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 	"testing"
@@ -27,3 +28,61 @@ func TestOpenT(t *testing.T) {
 		assert.Nil(t, err)
 	})
 }
+
+func TestOpen(t *testing.T) {
+	t.Run("with url", func(t *testing.T) {
+		c, err := Open(map[string]any{
+			"url":          "redis://localhost:6379/0",
+			"pool_size":    5,
+			"dial_timeout": "1s",
+		})
+		if err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		assert.NoError(t, c.Set("key", "value", 0))
+		assert.True(t, c.Has("key"))
+		assert.NoError(t, c.(*Cache).Close())
+		assert.Error(t, c.Set("key", "value", 0))
+	})
+
+	t.Run("with addrs", func(t *testing.T) {
+		c, err := Open(map[string]any{
+			"addrs": []string{"localhost:6379"},
+		})
+		if err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		assert.NoError(t, c.Set("key", "value", 0))
+		assert.NoError(t, c.(*Cache).Close())
+	})
+
+	t.Run("with invalid url", func(t *testing.T) {
+		_, err := Open(map[string]any{
+			"url": "http://localhost:6379",
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("sentinel without master name", func(t *testing.T) {
+		_, err := Open(map[string]any{
+			"sentinel": map[string]any{
+				"addrs": []string{"localhost:26379"},
+			},
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("with client", func(t *testing.T) {
+		client := redis.NewClient(&redis.Options{
+			Addr: "localhost:6379",
+		})
+		c, err := Open(map[string]any{
+			"client": client,
+		})
+		if err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		assert.NoError(t, c.(*Cache).Close())
+		assert.NoError(t, client.Ping(context.Background()).Err())
+	})
+}
@@ -0,0 +1,247 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gopi-frame/cache"
+	"github.com/gopi-frame/exception"
+)
+
+type Cache struct {
+	db        *sql.DB
+	dialect   Dialect
+	prefix    string
+	expire    time.Duration
+	sliding   bool
+	tableName string
+
+	gcInterval time.Duration
+
+	getStmt    *sql.Stmt
+	upsertStmt *sql.Stmt
+	touchStmt  *sql.Stmt
+	deleteStmt *sql.Stmt
+	clearStmt  *sql.Stmt
+	gcStmt     *sql.Stmt
+
+	once sync.Once
+	done chan struct{}
+}
+
+// New creates a new cache, the cache table is created if it does not exist.
+func New(config *Config) (*Cache, error) {
+	if config.DB == nil {
+		return nil, exception.NewEmptyArgumentException("db")
+	}
+	dialect, ok := getDialect(config.Dialect)
+	if !ok {
+		return nil, exception.NewArgumentException("dialect", config.Dialect, fmt.Sprintf("unknown dialect \"%s\"", config.Dialect))
+	}
+	config.ApplyDefaults()
+	for _, statement := range dialect.CreateTable(config.TableName) {
+		if _, err := config.DB.Exec(statement); err != nil {
+			return nil, err
+		}
+	}
+	c := &Cache{
+		db:         config.DB,
+		dialect:    dialect,
+		prefix:     config.Prefix,
+		expire:     config.Expire,
+		sliding:    config.Sliding,
+		tableName:  config.TableName,
+		gcInterval: config.GCInterval,
+		done:       make(chan struct{}),
+	}
+	if err := c.prepare(); err != nil {
+		_ = c.Close()
+		return nil, err
+	}
+	go c.gc()
+	return c, nil
+}
+
+func (c *Cache) prepare() error {
+	table := c.dialect.Quote(c.tableName)
+	key := c.dialect.Quote("key")
+	value := c.dialect.Quote("value")
+	expire := c.dialect.Quote("expire")
+	statements := []struct {
+		stmt  **sql.Stmt
+		query string
+	}{
+		{&c.getStmt, fmt.Sprintf("SELECT %s, %s FROM %s WHERE %s = %s", value, expire, table, key, c.dialect.Placeholder(1))},
+		{&c.upsertStmt, c.dialect.Upsert(c.tableName)},
+		{&c.touchStmt, fmt.Sprintf("UPDATE %s SET %s = %s WHERE %s IN (%s, %s) AND %s > %s", table, expire, c.dialect.Placeholder(1), key, c.dialect.Placeholder(2), c.dialect.Placeholder(3), expire, c.dialect.Placeholder(4))},
+		{&c.deleteStmt, fmt.Sprintf("DELETE FROM %s WHERE %s = %s", table, key, c.dialect.Placeholder(1))},
+		{&c.clearStmt, fmt.Sprintf("DELETE FROM %s WHERE %s LIKE %s", table, key, c.dialect.Placeholder(1))},
+		{&c.gcStmt, fmt.Sprintf("DELETE FROM %s WHERE %s < %s", table, expire, c.dialect.Placeholder(1))},
+	}
+	for _, statement := range statements {
+		stmt, err := c.db.Prepare(statement.query)
+		if err != nil {
+			return err
+		}
+		*statement.stmt = stmt
+	}
+	return nil
+}
+
+func (c *Cache) buildKey(key string) string {
+	return c.prefix + ":" + key
+}
+
+// buildTTLKey returns the key of the row holding the expire time key was set with, written in sliding mode.
+func (c *Cache) buildTTLKey(key string) string {
+	return c.buildKey(key) + "\x1fttl"
+}
+
+func (c *Cache) gc() {
+	ticker := time.NewTicker(c.gcInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			_, _ = c.gcStmt.Exec(time.Now().UnixMilli())
+		}
+	}
+}
+
+func (c *Cache) Get(key string) (string, error) {
+	var value []byte
+	var expire int64
+	if err := c.getStmt.QueryRow(c.buildKey(key)).Scan(&value, &expire); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", cache.ErrCacheNotFound
+		}
+		return "", err
+	}
+	if expire <= time.Now().UnixMilli() {
+		_, _ = c.deleteStmt.Exec(c.buildKey(key))
+		return "", cache.ErrCacheNotFound
+	}
+	if c.sliding {
+		if err := c.touch(key); err != nil {
+			return "", err
+		}
+	}
+	return string(value), nil
+}
+
+// touch extends the expire time of key by the expire time it was set with.
+// Values set without sliding are extended by the default expire time. The expire time is checked
+// by the update, so values which expire in the meantime are not extended.
+func (c *Cache) touch(key string) error {
+	expire := c.expire
+	var ttl []byte
+	var ttlExpire int64
+	if err := c.getStmt.QueryRow(c.buildTTLKey(key)).Scan(&ttl, &ttlExpire); err == nil {
+		ms, err := strconv.ParseInt(string(ttl), 10, 64)
+		if err != nil {
+			return err
+		}
+		expire = time.Duration(ms) * time.Millisecond
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	now := time.Now()
+	result, err := c.touchStmt.Exec(now.Add(expire).UnixMilli(), c.buildKey(key), c.buildTTLKey(key), now.UnixMilli())
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return cache.ErrCacheNotFound
+	}
+	return nil
+}
+
+func (c *Cache) Set(key string, value string, expire time.Duration) error {
+	if expire <= 0 {
+		expire = c.expire
+	}
+	expireAt := time.Now().Add(expire).UnixMilli()
+	if !c.sliding {
+		_, err := c.upsertStmt.Exec(c.buildKey(key), []byte(value), expireAt)
+		return err
+	}
+	tx, err := c.db.Begin()
+	if err != nil {
+		return err
+	}
+	upsert := tx.Stmt(c.upsertStmt)
+	if _, err := upsert.Exec(c.buildKey(key), []byte(value), expireAt); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if _, err := upsert.Exec(c.buildTTLKey(key), []byte(strconv.FormatInt(expire.Milliseconds(), 10)), expireAt); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (c *Cache) Load(key string, loader func() (string, error), expire time.Duration) (string, error) {
+	if v, err := c.Get(key); err == nil {
+		return v, nil
+	}
+	v, err := loader()
+	if err != nil {
+		return "", err
+	}
+	if err := c.Set(key, v, expire); err != nil {
+		return "", err
+	}
+	return v, nil
+}
+
+// Has reports whether key exists, it does not extend the expire time in sliding mode.
+func (c *Cache) Has(key string) bool {
+	var value []byte
+	var expire int64
+	if err := c.getStmt.QueryRow(c.buildKey(key)).Scan(&value, &expire); err != nil {
+		return false
+	}
+	return expire > time.Now().UnixMilli()
+}
+
+func (c *Cache) Delete(key string) error {
+	if c.sliding {
+		if _, err := c.deleteStmt.Exec(c.buildTTLKey(key)); err != nil {
+			return err
+		}
+	}
+	_, err := c.deleteStmt.Exec(c.buildKey(key))
+	return err
+}
+
+func (c *Cache) Clear() error {
+	_, err := c.clearStmt.Exec(c.prefix + ":%")
+	return err
+}
+
+// CheckHealth pings the database.
+func (c *Cache) CheckHealth(ctx context.Context) error {
+	return c.db.PingContext(ctx)
+}
+
+// Close stops the garbage collection and closes the prepared statements, the database connection is left open.
+func (c *Cache) Close() error {
+	c.once.Do(func() {
+		close(c.done)
+	})
+	var errs []error
+	for _, stmt := range []*sql.Stmt{c.getStmt, c.upsertStmt, c.touchStmt, c.deleteStmt, c.clearStmt, c.gcStmt} {
+		if stmt != nil {
+			errs = append(errs, stmt.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,262 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/gopi-frame/cache"
+	cc "github.com/gopi-frame/contract/cache"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+var testCache cc.Cache
+
+func TestMain(m *testing.M) {
+	db, err := sql.Open("sqlite3", "file::memory:?cache=shared")
+	if err != nil {
+		panic(err)
+	}
+	c, err := Open(map[string]any{
+		"db":         db,
+		"dialect":    "sqlite",
+		"table_name": "test_caches",
+		"expire":     time.Second * 2,
+	})
+	if err != nil {
+		panic(err)
+	}
+	if err := c.Clear(); err != nil {
+		panic(err)
+	}
+	testCache = c
+	code := m.Run()
+	_ = c.(*Cache).Close()
+	_ = db.Close()
+	os.Exit(code)
+}
+
+func TestCache_Set(t *testing.T) {
+	t.Run("with expire", func(t *testing.T) {
+		if err := testCache.Set("key", "value", time.Second); err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		assert.True(t, testCache.Has("key"))
+		time.Sleep(time.Second)
+		assert.False(t, testCache.Has("key"))
+	})
+
+	t.Run("without expire", func(t *testing.T) {
+		if err := testCache.Set("key", "value", 0); err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		assert.True(t, testCache.Has("key"))
+		time.Sleep(time.Second * 2)
+		assert.False(t, testCache.Has("key"))
+	})
+}
+
+func TestCache_Get(t *testing.T) {
+	t.Run("cache not exist", func(t *testing.T) {
+		_, err := testCache.Get("key")
+		assert.ErrorIs(t, err, cache.ErrCacheNotFound)
+	})
+
+	t.Run("cache exist", func(t *testing.T) {
+		if err := testCache.Set("key", "value", 0); err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		v, err := testCache.Get("key")
+		assert.NoError(t, err)
+		assert.Equal(t, "value", v)
+	})
+
+	t.Run("cache out of date", func(t *testing.T) {
+		if err := testCache.Set("key", "value", time.Second); err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		time.Sleep(time.Second)
+		_, err := testCache.Get("key")
+		assert.ErrorIs(t, err, cache.ErrCacheNotFound)
+	})
+}
+
+func TestCache_Load(t *testing.T) {
+	t.Run("cache not exist", func(t *testing.T) {
+		if value, err := testCache.Load("key", func() (string, error) {
+			return "value", nil
+		}, 0); err != nil {
+			assert.FailNow(t, err.Error())
+		} else {
+			assert.Equal(t, "value", value)
+		}
+	})
+
+	t.Run("cache out of date", func(t *testing.T) {
+		if err := testCache.Set("key", "value", time.Second); err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		time.Sleep(time.Second)
+		if value, err := testCache.Load("key", func() (string, error) {
+			return "value", nil
+		}, 0); err != nil {
+			assert.FailNow(t, err.Error())
+		} else {
+			assert.Equal(t, "value", value)
+		}
+	})
+
+	t.Run("cache exists", func(t *testing.T) {
+		if err := testCache.Set("key", "value", 0); err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		if value, err := testCache.Load("key", func() (string, error) {
+			return "value1", nil
+		}, 0); err != nil {
+			assert.FailNow(t, err.Error())
+		} else {
+			assert.Equal(t, "value", value)
+		}
+	})
+}
+
+func TestCache_Delete(t *testing.T) {
+	t.Run("file not exist", func(t *testing.T) {
+		if err := testCache.Delete("key"); err != nil {
+			assert.FailNow(t, err.Error())
+		}
+	})
+
+	t.Run("file exists", func(t *testing.T) {
+		if err := testCache.Set("key", "value", 0); err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		if err := testCache.Delete("key"); err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		assert.False(t, testCache.Has("key"))
+	})
+}
+
+func TestCache_Clear(t *testing.T) {
+	if err := testCache.Set("key", "value", 0); err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	if err := testCache.Set("key2", "value", 0); err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	if err := testCache.Clear(); err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	assert.False(t, testCache.Has("key"))
+	assert.False(t, testCache.Has("key2"))
+}
+
+func TestCache_SetConcurrently(t *testing.T) {
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- testCache.Set("key", fmt.Sprintf("value%d", i), 0)
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+	assert.True(t, testCache.Has("key"))
+}
+
+func TestCache_Binary(t *testing.T) {
+	if err := testCache.Set("key", "\x00\xff\x10", 0); err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	v, err := testCache.Get("key")
+	assert.NoError(t, err)
+	assert.Equal(t, "\x00\xff\x10", v)
+}
+
+func TestCache_CheckHealth(t *testing.T) {
+	checker, ok := testCache.(cache.HealthChecker)
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.NoError(t, checker.CheckHealth(context.Background()))
+}
+
+func TestCache_Sliding(t *testing.T) {
+	db := testCache.(*Cache).db
+	c, err := New(&Config{DB: db, Dialect: "sqlite", TableName: "test_sliding_caches", Expire: time.Minute, Sliding: true})
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	defer func() {
+		_ = c.Close()
+	}()
+	expire := func(key string) time.Time {
+		var expire int64
+		if err := db.QueryRow("SELECT expire FROM test_sliding_caches WHERE key = ?", key).Scan(&expire); err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		return time.UnixMilli(expire)
+	}
+	setExpire := func(key string, expire time.Time) {
+		if _, err := db.Exec("UPDATE test_sliding_caches SET expire = ? WHERE key = ?", expire.UnixMilli(), key); err != nil {
+			assert.FailNow(t, err.Error())
+		}
+	}
+
+	if err := c.Set("key", "value", time.Hour); err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	setExpire(c.buildKey("key"), time.Now().Add(time.Second))
+	assert.True(t, c.Has("key"))
+	assert.WithinDuration(t, time.Now().Add(time.Second), expire(c.buildKey("key")), time.Second/2)
+	v, err := c.Get("key")
+	assert.NoError(t, err)
+	assert.Equal(t, "value", v)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), expire(c.buildKey("key")), time.Second)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), expire(c.buildTTLKey("key")), time.Second)
+
+	if _, err := db.Exec("INSERT INTO test_sliding_caches (key, value, expire) VALUES (?, ?, ?)", c.buildKey("legacy"), []byte("value"), time.Now().Add(time.Second).UnixMilli()); err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	_, err = c.Get("legacy")
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), expire(c.buildKey("legacy")), time.Second)
+
+	setExpire(c.buildKey("key"), time.Now().Add(-time.Second))
+	setExpire(c.buildTTLKey("key"), time.Now().Add(-time.Second))
+	assert.ErrorIs(t, c.touch("key"), cache.ErrCacheNotFound)
+	assert.False(t, c.Has("key"))
+	assert.True(t, expire(c.buildKey("key")).Before(time.Now()))
+
+	assert.NoError(t, c.Delete("key"))
+	var count int
+	assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM test_sliding_caches WHERE key LIKE ?", c.buildKey("key")+"%").Scan(&count))
+	assert.Zero(t, count)
+}
+
+func TestCache_GC(t *testing.T) {
+	db := testCache.(*Cache).db
+	c, err := New(&Config{DB: db, Dialect: "sqlite", TableName: "test_gc_caches", GCInterval: time.Millisecond * 50})
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	defer func() {
+		_ = c.Close()
+	}()
+	assert.NoError(t, c.Set("expired", "value", time.Millisecond))
+	assert.NoError(t, c.Set("alive", "value", time.Hour))
+	time.Sleep(time.Millisecond * 200)
+	var count int
+	assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM test_gc_caches").Scan(&count))
+	assert.Equal(t, 1, count)
+}
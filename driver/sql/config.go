@@ -0,0 +1,41 @@
+package sql
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Config is the sql cache config.
+type Config struct {
+	// DB is the database connection.
+	DB *sql.DB `json:"db" yaml:"db" toml:"db" mapstructure:"db"`
+	// Dialect is the sql dialect of the database, one of "sqlite", "postgres" and "mysql".
+	Dialect string `json:"dialect" yaml:"dialect" toml:"dialect" mapstructure:"dialect"`
+	// Prefix is the cache prefix.
+	Prefix string `json:"prefix" yaml:"prefix" toml:"prefix" mapstructure:"prefix"`
+	// Expire is the default cache expire time, default is 72 hour.
+	Expire time.Duration `json:"expire" yaml:"expire" toml:"expire" mapstructure:"expire"`
+	// Sliding makes Get extend the expire time of values by the expire time they were set with.
+	// The expire time is stored in a separate row next to the value.
+	Sliding bool `json:"sliding" yaml:"sliding" toml:"sliding" mapstructure:"sliding"`
+	// TableName is the cache table name, default is "caches".
+	TableName string `json:"table_name" yaml:"table_name" toml:"table_name" mapstructure:"table_name"`
+	// GCInterval is the interval of deleting expired rows, default is 1 minute.
+	GCInterval time.Duration `json:"gc_interval" yaml:"gc_interval" toml:"gc_interval" mapstructure:"gc_interval"`
+}
+
+// ApplyDefaults sets the default values of unset fields.
+func (c *Config) ApplyDefaults() {
+	if c.Prefix == "" {
+		c.Prefix = "cache"
+	}
+	if c.Expire <= 0 {
+		c.Expire = time.Hour * 72
+	}
+	if c.TableName == "" {
+		c.TableName = "caches"
+	}
+	if c.GCInterval <= 0 {
+		c.GCInterval = time.Minute
+	}
+}
@@ -0,0 +1,125 @@
+package sql
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/gopi-frame/exception"
+)
+
+// Dialect builds the statements of the cache for a database.
+// The cache table has a "key" primary key, a binary "value" column and an "expire" column
+// holding the expire time as unix milliseconds.
+type Dialect interface {
+	// Quote quotes an identifier.
+	Quote(identifier string) string
+	// Placeholder returns the placeholder of the n-th argument, starting from 1.
+	Placeholder(n int) string
+	// CreateTable returns the statements which create the cache table if it does not exist.
+	CreateTable(table string) []string
+	// Upsert returns the statement which inserts or updates the key, value and expire of a cache.
+	Upsert(table string) string
+}
+
+var dialects = map[string]Dialect{
+	"sqlite":   sqliteDialect{},
+	"postgres": postgresDialect{},
+	"mysql":    mysqlDialect{},
+}
+
+var dialectsMu sync.RWMutex
+
+// RegisterDialect registers dialect
+func RegisterDialect(name string, dialect Dialect) {
+	dialectsMu.Lock()
+	defer dialectsMu.Unlock()
+	if dialect == nil {
+		panic(exception.NewEmptyArgumentException("dialect"))
+	}
+	if _, ok := dialects[name]; ok {
+		panic(exception.NewArgumentException("name", name, fmt.Sprintf("duplicate dialect \"%s\"", name)))
+	}
+	dialects[name] = dialect
+}
+
+func getDialect(name string) (Dialect, bool) {
+	dialectsMu.RLock()
+	defer dialectsMu.RUnlock()
+	dialect, ok := dialects[name]
+	return dialect, ok
+}
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) Quote(identifier string) string {
+	return `"` + strings.ReplaceAll(identifier, `"`, `""`) + `"`
+}
+
+func (sqliteDialect) Placeholder(int) string {
+	return "?"
+}
+
+func (d sqliteDialect) CreateTable(table string) []string {
+	return []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (%s VARCHAR(255) NOT NULL PRIMARY KEY, %s BLOB NOT NULL, %s BIGINT NOT NULL)`,
+			d.Quote(table), d.Quote("key"), d.Quote("value"), d.Quote("expire")),
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s ON %s (%s)`,
+			d.Quote("idx_"+table+"_expire"), d.Quote(table), d.Quote("expire")),
+	}
+}
+
+func (d sqliteDialect) Upsert(table string) string {
+	return fmt.Sprintf(`INSERT INTO %s (%s, %s, %s) VALUES (?, ?, ?) ON CONFLICT (%s) DO UPDATE SET %s = excluded.%s, %s = excluded.%s`,
+		d.Quote(table), d.Quote("key"), d.Quote("value"), d.Quote("expire"),
+		d.Quote("key"), d.Quote("value"), d.Quote("value"), d.Quote("expire"), d.Quote("expire"))
+}
+
+type postgresDialect struct{}
+
+func (postgresDialect) Quote(identifier string) string {
+	return `"` + strings.ReplaceAll(identifier, `"`, `""`) + `"`
+}
+
+func (postgresDialect) Placeholder(n int) string {
+	return fmt.Sprintf("$%d", n)
+}
+
+func (d postgresDialect) CreateTable(table string) []string {
+	return []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (%s VARCHAR(255) NOT NULL PRIMARY KEY, %s BYTEA NOT NULL, %s BIGINT NOT NULL)`,
+			d.Quote(table), d.Quote("key"), d.Quote("value"), d.Quote("expire")),
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s ON %s (%s)`,
+			d.Quote("idx_"+table+"_expire"), d.Quote(table), d.Quote("expire")),
+	}
+}
+
+func (d postgresDialect) Upsert(table string) string {
+	return fmt.Sprintf(`INSERT INTO %s (%s, %s, %s) VALUES ($1, $2, $3) ON CONFLICT (%s) DO UPDATE SET %s = EXCLUDED.%s, %s = EXCLUDED.%s`,
+		d.Quote(table), d.Quote("key"), d.Quote("value"), d.Quote("expire"),
+		d.Quote("key"), d.Quote("value"), d.Quote("value"), d.Quote("expire"), d.Quote("expire"))
+}
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) Quote(identifier string) string {
+	return "`" + strings.ReplaceAll(identifier, "`", "``") + "`"
+}
+
+func (mysqlDialect) Placeholder(int) string {
+	return "?"
+}
+
+func (d mysqlDialect) CreateTable(table string) []string {
+	return []string{
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s VARCHAR(255) NOT NULL PRIMARY KEY, %s LONGBLOB NOT NULL, %s BIGINT NOT NULL, INDEX %s (%s))",
+			d.Quote(table), d.Quote("key"), d.Quote("value"), d.Quote("expire"),
+			d.Quote("idx_"+table+"_expire"), d.Quote("expire")),
+	}
+}
+
+func (d mysqlDialect) Upsert(table string) string {
+	return fmt.Sprintf("INSERT INTO %s (%s, %s, %s) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE %s = VALUES(%s), %s = VALUES(%s)",
+		d.Quote(table), d.Quote("key"), d.Quote("value"), d.Quote("expire"),
+		d.Quote("value"), d.Quote("value"), d.Quote("expire"), d.Quote("expire"))
+}
@@ -0,0 +1,43 @@
+package sql
+
+import (
+	"github.com/gopi-frame/cache"
+	cc "github.com/gopi-frame/contract/cache"
+)
+
+// This variable can be replaced through `go build -ldflags=-X github.com/gopi-frame/cache/driver/sql.driverName=custom`
+var driverName = "sql"
+
+//goland:noinspection GoBoolExpressions
+func init() {
+	if driverName != "" {
+		cache.Register(driverName, &Driver{})
+	}
+}
+
+type Driver struct{}
+
+func (d *Driver) Open(config map[string]any) (cc.Cache, error) {
+	var cfg Config
+	err := cache.DecodeConfig(config, &cfg, cache.WithAliases(map[string]string{"table": "table_name"}))
+	if err != nil {
+		return nil, err
+	}
+	c, err := New(&cfg)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func Open(config map[string]any) (cc.Cache, error) {
+	return (new(Driver)).Open(config)
+}
+
+func OpenT[T any](config map[string]any, opts ...cache.Option[T]) (*cache.Cache[T], error) {
+	c, err := Open(config)
+	if err != nil {
+		return nil, err
+	}
+	return cache.New[T](c, opts...)
+}
@@ -0,0 +1,83 @@
+package sql
+
+import (
+	"database/sql"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestOpenT(t *testing.T) {
+	t.Run("without db", func(t *testing.T) {
+		_, err := OpenT[any](map[string]any{
+			"dialect": "sqlite",
+			"expire":  time.Second * 2,
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("unknown dialect", func(t *testing.T) {
+		db, err := sql.Open("sqlite3", "file::memory:?cache=shared")
+		if err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		_, err = OpenT[any](map[string]any{
+			"db":      db,
+			"dialect": "oracle",
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("create table error", func(t *testing.T) {
+		db, err := sql.Open("sqlite3", "file:create_table_error?mode=memory&cache=shared")
+		if err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		assert.NoError(t, db.Close())
+		_, err = OpenT[any](map[string]any{
+			"db":      db,
+			"dialect": "sqlite",
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("with db", func(t *testing.T) {
+		db, err := sql.Open("sqlite3", "file::memory:?cache=shared")
+		if err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		_, err = OpenT[any](map[string]any{
+			"db":      db,
+			"dialect": "sqlite",
+			"expire":  time.Second * 2,
+		})
+		assert.Nil(t, err)
+	})
+}
+
+func TestDialects(t *testing.T) {
+	t.Run("postgres", func(t *testing.T) {
+		d, _ := getDialect("postgres")
+		assert.Equal(t, `"ca""ches"`, d.Quote(`ca"ches`))
+		assert.Equal(t, "$2", d.Placeholder(2))
+		assert.Equal(t, `INSERT INTO "caches" ("key", "value", "expire") VALUES ($1, $2, $3) ON CONFLICT ("key") DO UPDATE SET "value" = EXCLUDED."value", "expire" = EXCLUDED."expire"`, d.Upsert("caches"))
+		assert.Contains(t, d.CreateTable("caches")[0], `"value" BYTEA NOT NULL`)
+	})
+
+	t.Run("mysql", func(t *testing.T) {
+		d, _ := getDialect("mysql")
+		assert.Equal(t, "`caches`", d.Quote("caches"))
+		assert.Equal(t, "?", d.Placeholder(2))
+		assert.Equal(t, "INSERT INTO `caches` (`key`, `value`, `expire`) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE `value` = VALUES(`value`), `expire` = VALUES(`expire`)", d.Upsert("caches"))
+		assert.Contains(t, d.CreateTable("caches")[0], "INDEX `idx_caches_expire` (`expire`)")
+	})
+
+	t.Run("register", func(t *testing.T) {
+		assert.Panics(t, func() {
+			RegisterDialect("sqlite", sqliteDialect{})
+		})
+		assert.Panics(t, func() {
+			RegisterDialect("custom", nil)
+		})
+	})
+}
@@ -0,0 +1,206 @@
+package cache
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/gopi-frame/contract/cache"
+	"github.com/gopi-frame/exception"
+)
+
+// encryptionEnvelopeVersion is the first byte of encrypted values.
+const encryptionEnvelopeVersion byte = 0x01
+
+func init() {
+	RegisterMiddleware("encrypt", func(_ string, options map[string]any) (Middleware, error) {
+		config := new(EncryptionConfig)
+		if err := DecodeConfig(options, config); err != nil {
+			return nil, err
+		}
+		keyring, err := config.Keyring()
+		if err != nil {
+			return nil, err
+		}
+		return EncryptionMiddleware(keyring), nil
+	})
+}
+
+// EncryptionConfig is the encryption config.
+type EncryptionConfig struct {
+	// CurrentKey is the id of the key used to encrypt values.
+	CurrentKey string `json:"current_key" yaml:"current_key" toml:"current_key" mapstructure:"current_key"`
+	// Keys are the base64 encoded AES keys of 16, 24 or 32 bytes, keyed by id.
+	// Keep the retired keys until the values encrypted with them have expired.
+	Keys map[string]string `json:"keys" yaml:"keys" toml:"keys" mapstructure:"keys"`
+}
+
+// Keyring creates the keyring from the config.
+func (c *EncryptionConfig) Keyring() (*Keyring, error) {
+	keys := make(map[string][]byte, len(c.Keys))
+	for id, encoded := range c.Keys {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, exception.NewArgumentException("keys", id, "key must be base64 encoded")
+		}
+		keys[id] = key
+	}
+	return NewKeyring(c.CurrentKey, keys)
+}
+
+// Keyring holds the AES-GCM keys by id.
+// Values are encrypted with the current key and decrypted with the key recorded in their envelope,
+// so keys can be rotated by adding a new key, making it current and removing the old key once unused.
+type Keyring struct {
+	current string
+	aeads   map[string]cipher.AEAD
+}
+
+// NewKeyring creates a keyring, every key must be 16, 24 or 32 bytes long to select AES-128, AES-192 or AES-256.
+func NewKeyring(current string, keys map[string][]byte) (*Keyring, error) {
+	if current == "" {
+		return nil, exception.NewEmptyArgumentException("current")
+	}
+	if _, ok := keys[current]; !ok {
+		return nil, exception.NewArgumentException("current", current, fmt.Sprintf("unknown key \"%s\"", current))
+	}
+	k := &Keyring{current: current, aeads: make(map[string]cipher.AEAD, len(keys))}
+	for id, key := range keys {
+		if id == "" || len(id) > 255 {
+			return nil, exception.NewArgumentException("keys", id, "key id must be 1 to 255 bytes long")
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, exception.NewArgumentException("keys", id, err.Error())
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		k.aeads[id] = aead
+	}
+	return k, nil
+}
+
+// Current returns the id of the key used to encrypt values.
+func (k *Keyring) Current() string {
+	return k.current
+}
+
+// Encrypt encrypts value into an envelope holding the version, the key id, the nonce and the sealed value.
+// The cache key is authenticated along with the value, so the envelope cannot be moved to another key.
+func (k *Keyring) Encrypt(key string, value []byte) ([]byte, error) {
+	aead := k.aeads[k.current]
+	header := append([]byte{encryptionEnvelopeVersion, byte(len(k.current))}, k.current...)
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	envelope := append(header[:len(header):len(header)], nonce...)
+	return aead.Seal(envelope, nonce, value, append(header[:len(header):len(header)], key...)), nil
+}
+
+// Decrypt decrypts an envelope created by [Keyring.Encrypt] for the same cache key.
+// It returns a [DecryptionException] if the envelope is malformed, the key is unknown or authentication fails.
+func (k *Keyring) Decrypt(key string, envelope []byte) ([]byte, error) {
+	if len(envelope) < 2 || envelope[0] != encryptionEnvelopeVersion {
+		return nil, NewDecryptionException("", "unsupported envelope")
+	}
+	end := 2 + int(envelope[1])
+	if len(envelope) < end {
+		return nil, NewDecryptionException("", "truncated envelope")
+	}
+	id := string(envelope[2:end])
+	aead, ok := k.aeads[id]
+	if !ok {
+		return nil, NewDecryptionException(id, "unknown key")
+	}
+	if len(envelope) < end+aead.NonceSize()+aead.Overhead() {
+		return nil, NewDecryptionException(id, "truncated envelope")
+	}
+	header := envelope[:end:end]
+	nonce := envelope[end : end+aead.NonceSize()]
+	value, err := aead.Open(nil, nonce, envelope[end+aead.NonceSize():], append(header, key...))
+	if err != nil {
+		return nil, NewDecryptionException(id, "authentication failed")
+	}
+	return value, nil
+}
+
+// EncryptionCache encrypts the values of a store with AES-GCM.
+// When combined with compression, compression has to be applied first, that is, it has to wrap this cache.
+type EncryptionCache struct {
+	Forwarder
+	keyring *Keyring
+}
+
+// NewEncryptionCache creates a cache which encrypts the values of store with keyring.
+func NewEncryptionCache(store cache.Cache, keyring *Keyring) *EncryptionCache {
+	return &EncryptionCache{
+		Forwarder: Forwarder{Next: store},
+		keyring:   keyring,
+	}
+}
+
+// EncryptionMiddleware returns a middleware which wraps stores with [NewEncryptionCache].
+func EncryptionMiddleware(keyring *Keyring) Middleware {
+	return func(next cache.Cache) cache.Cache {
+		return NewEncryptionCache(next, keyring)
+	}
+}
+
+func (c *EncryptionCache) Get(key string) (string, error) {
+	value, err := c.Next.Get(key)
+	if err != nil {
+		return "", err
+	}
+	bs, err := c.keyring.Decrypt(key, []byte(value))
+	if err != nil {
+		return "", err
+	}
+	return string(bs), nil
+}
+
+func (c *EncryptionCache) Set(key string, value string, expire time.Duration) error {
+	bs, err := c.keyring.Encrypt(key, []byte(value))
+	if err != nil {
+		return err
+	}
+	return c.Next.Set(key, string(bs), expire)
+}
+
+func (c *EncryptionCache) Load(key string, loader func() (string, error), expire time.Duration) (string, error) {
+	value, err := c.Next.Load(key, func() (string, error) {
+		value, err := loader()
+		if err != nil {
+			return "", err
+		}
+		bs, err := c.keyring.Encrypt(key, []byte(value))
+		if err != nil {
+			return "", err
+		}
+		return string(bs), nil
+	}, expire)
+	if err != nil {
+		return "", err
+	}
+	bs, err := c.keyring.Decrypt(key, []byte(value))
+	if err != nil {
+		return "", err
+	}
+	return string(bs), nil
+}
+
+// WithEncryption encrypts the encoded values of the cache with keyring.
+func WithEncryption[T any](keyring *Keyring) OptionFunc[T] {
+	return func(c *Cache[T]) error {
+		if keyring == nil {
+			return exception.NewEmptyArgumentException("keyring")
+		}
+		c.Cache = NewEncryptionCache(c.Cache, keyring)
+		return nil
+	}
+}
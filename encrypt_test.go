@@ -0,0 +1,122 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptionCache(t *testing.T) {
+	oldKey := bytes.Repeat([]byte{1}, 32)
+	newKey := bytes.Repeat([]byte{2}, 16)
+
+	t.Run("round trip", func(t *testing.T) {
+		keyring, err := NewKeyring("v1", map[string][]byte{"v1": oldKey})
+		if err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		store := newMapStore()
+		c := NewEncryptionCache(store, keyring)
+		for _, value := range []string{"", "secret", string([]byte{0, 1, 0xff})} {
+			assert.NoError(t, c.Set("key", value, 0))
+			raw, _ := store.Get("key")
+			assert.NotContains(t, raw, "secret")
+			got, err := c.Get("key")
+			assert.NoError(t, err)
+			assert.Equal(t, value, got)
+		}
+		value, err := c.Load("loaded", func() (string, error) { return "secret", nil }, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, "secret", value)
+		value, err = c.Load("loaded", func() (string, error) { return "", nil }, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, "secret", value)
+	})
+
+	t.Run("rotation", func(t *testing.T) {
+		store := newMapStore()
+		oldKeyring, _ := NewKeyring("v1", map[string][]byte{"v1": oldKey})
+		assert.NoError(t, NewEncryptionCache(store, oldKeyring).Set("key", "secret", 0))
+		newKeyring, _ := NewKeyring("v2", map[string][]byte{"v1": oldKey, "v2": newKey})
+		c := NewEncryptionCache(store, newKeyring)
+		value, err := c.Get("key")
+		assert.NoError(t, err)
+		assert.Equal(t, "secret", value)
+		assert.NoError(t, c.Set("key", "secret", 0))
+		_, err = NewEncryptionCache(store, oldKeyring).Get("key")
+		var decryptionErr *DecryptionException
+		if assert.ErrorAs(t, err, &decryptionErr) {
+			assert.Equal(t, "v2", decryptionErr.KeyID())
+		}
+	})
+
+	t.Run("authentication failure", func(t *testing.T) {
+		keyring, _ := NewKeyring("v1", map[string][]byte{"v1": oldKey})
+		store := newMapStore()
+		c := NewEncryptionCache(store, keyring)
+		assert.NoError(t, c.Set("key", "secret", 0))
+		raw, _ := store.Get("key")
+		tampered := []byte(raw)
+		tampered[len(tampered)-1] ^= 0xff
+		assert.NoError(t, store.Set("key", string(tampered), 0))
+		_, err := c.Get("key")
+		assert.IsType(t, new(DecryptionException), err)
+
+		// moved to another key
+		assert.NoError(t, store.Set("other", raw, 0))
+		_, err = c.Get("other")
+		assert.IsType(t, new(DecryptionException), err)
+
+		assert.NoError(t, store.Set("plain", "secret", 0))
+		_, err = c.Get("plain")
+		assert.IsType(t, new(DecryptionException), err)
+	})
+
+	t.Run("invalid keyring", func(t *testing.T) {
+		_, err := NewKeyring("", map[string][]byte{"v1": oldKey})
+		assert.Error(t, err)
+		_, err = NewKeyring("v2", map[string][]byte{"v1": oldKey})
+		assert.Error(t, err)
+		_, err = NewKeyring("v1", map[string][]byte{"v1": []byte("short")})
+		assert.Error(t, err)
+	})
+
+	t.Run("typed cache", func(t *testing.T) {
+		keyring, _ := NewKeyring("v1", map[string][]byte{"v1": oldKey})
+		c, err := New[map[string]string](newMapStore(), WithEncryption[map[string]string](keyring))
+		if err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		assert.NoError(t, c.Set("key", map[string]string{"email": "user@example.com"}, 0))
+		value, err := c.Get("key")
+		assert.NoError(t, err)
+		assert.Equal(t, "user@example.com", value["email"])
+	})
+
+	t.Run("middleware", func(t *testing.T) {
+		manager, err := OpenCacheManager(map[string]any{
+			"stores": map[string]any{
+				"map": map[string]any{
+					"driver": "test-map",
+					"middlewares": []any{
+						"compress",
+						map[string]any{
+							"name":        "encrypt",
+							"current_key": "v1",
+							"keys":        map[string]any{"v1": base64.StdEncoding.EncodeToString(oldKey)},
+						},
+					},
+				},
+			},
+		})
+		if err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		assert.NoError(t, manager.Set("key", "secret", 0))
+		value, err := manager.Get("key")
+		assert.NoError(t, err)
+		assert.Equal(t, "secret", value)
+	})
+}
@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"encoding/binary"
+	"sync"
+	"time"
+)
+
+// entryPrefix starts the values wrapped in an entry envelope, it is followed by
+// the uvarint encoded soft expiry, hard expiry and delta in milliseconds, and the value.
+var entryPrefix = envelopePrefix(envelopeEntry)
+
+// entry is a value with its expiry metadata, stored by the middlewares which decide
+// when to refresh a value before the store expires it.
+type entry struct {
+	value string
+	// soft is when the value becomes stale.
+	soft time.Time
+	// hard is when the value must no longer be served.
+	hard time.Time
+	// delta is the time taken to load the value.
+	delta time.Duration
+}
+
+func (e entry) encode() string {
+	bs := make([]byte, 0, len(entryPrefix)+3*binary.MaxVarintLen64+len(e.value))
+	bs = append(bs, entryPrefix...)
+	bs = binary.AppendUvarint(bs, uint64(e.soft.UnixMilli()))
+	bs = binary.AppendUvarint(bs, uint64(e.hard.UnixMilli()))
+	bs = binary.AppendUvarint(bs, uint64(e.delta.Milliseconds()))
+	return string(append(bs, e.value...))
+}
+
+// decodeEntry decodes an entry envelope, ok is false if value is not an envelope.
+func decodeEntry(value string) (e entry, ok bool) {
+	payload, ok := unwrapEnvelope(envelopeEntry, value)
+	if !ok {
+		return e, false
+	}
+	bs := []byte(payload)
+	var fields [3]uint64
+	for i := range fields {
+		v, n := binary.Uvarint(bs)
+		if n <= 0 {
+			return e, false
+		}
+		fields[i] = v
+		bs = bs[n:]
+	}
+	return entry{
+		value: string(bs),
+		soft:  time.UnixMilli(int64(fields[0])),
+		hard:  time.UnixMilli(int64(fields[1])),
+		delta: time.Duration(fields[2]) * time.Millisecond,
+	}, true
+}
+
+// flight is an in-progress load shared by concurrent callers.
+type flight struct {
+	done  chan struct{}
+	value string
+	err   error
+}
+
+// flights deduplicates concurrent loads of the same key.
+type flights struct {
+	mu    sync.Mutex
+	calls map[string]*flight
+}
+
+func newFlights() *flights {
+	return &flights{calls: make(map[string]*flight)}
+}
+
+// do runs fn unless a call for key is in progress, and returns the result of the call.
+func (f *flights) do(key string, fn func() (string, error)) (string, error) {
+	call, owner := f.start(key)
+	if owner {
+		func() {
+			defer f.finish(key, call)
+			call.value, call.err = fn()
+		}()
+	}
+	<-call.done
+	return call.value, call.err
+}
+
+// start returns the call in progress for key, or starts a new one owned by the caller.
+func (f *flights) start(key string) (*flight, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if call, ok := f.calls[key]; ok {
+		return call, false
+	}
+	call := &flight{done: make(chan struct{})}
+	f.calls[key] = call
+	return call, true
+}
+
+func (f *flights) finish(key string, call *flight) {
+	f.mu.Lock()
+	delete(f.calls, key)
+	f.mu.Unlock()
+	close(call.done)
+}
@@ -0,0 +1,33 @@
+package cache
+
+import "strings"
+
+// envelopeMagic starts the values wrapped by the middlewares and options of this package, it is followed by
+// a kind byte telling which of them wrote the value. 0xC1 never occurs in UTF-8 text, so text values such as JSON
+// cannot start with it, and the following bytes make a collision with binary values implausible.
+// Values without the magic are passed through as they are.
+const envelopeMagic = "\xc1gpf"
+
+// Kinds of envelopes.
+const (
+	envelopeCompression byte = 'z'
+	envelopeCodec       byte = 'c'
+	envelopeEntry       byte = 'e'
+	envelopeNegative    byte = 'n'
+	envelopeVersion     byte = 'v'
+)
+
+// envelopePrefix returns the prefix of the envelopes of kind.
+func envelopePrefix(kind byte) string {
+	return envelopeMagic + string(kind)
+}
+
+// wrapEnvelope wraps payload in an envelope of kind.
+func wrapEnvelope(kind byte, payload string) string {
+	return envelopePrefix(kind) + payload
+}
+
+// unwrapEnvelope returns the payload of an envelope of kind, ok is false if value is not such an envelope.
+func unwrapEnvelope(kind byte, value string) (payload string, ok bool) {
+	return strings.CutPrefix(value, envelopePrefix(kind))
+}
@@ -21,3 +21,47 @@ func NewStoreNotConfiguredException(storeName string) *StoreNotConfiguredExcepti
 func (e *StoreNotConfiguredException) StoreName() string {
 	return e.storeName
 }
+
+// DecryptionException is returned when a cached value cannot be decrypted,
+// such as when it was tampered with or encrypted with an unknown key.
+type DecryptionException struct {
+	keyID string
+	Throwable
+}
+
+func NewDecryptionException(keyID string, reason string) *DecryptionException {
+	return &DecryptionException{
+		keyID:     keyID,
+		Throwable: exception.New(fmt.Sprintf("decrypt cache value with key [%s]: %s", keyID, reason)),
+	}
+}
+
+// KeyID returns the id of the key the value was encrypted with, it is empty if the envelope is malformed.
+func (e *DecryptionException) KeyID() string {
+	return e.keyID
+}
+
+// CodecMismatchException is returned when a cached value was written with another codec.
+type CodecMismatchException struct {
+	expected string
+	actual   string
+	Throwable
+}
+
+func NewCodecMismatchException(expected string, actual string) *CodecMismatchException {
+	return &CodecMismatchException{
+		expected:  expected,
+		actual:    actual,
+		Throwable: exception.New(fmt.Sprintf("cache value is encoded with codec [%s], expected [%s]", actual, expected)),
+	}
+}
+
+// Expected returns the name of the codec of the cache.
+func (e *CodecMismatchException) Expected() string {
+	return e.expected
+}
+
+// Actual returns the name of the codec the value was written with, it is empty if the name is truncated.
+func (e *CodecMismatchException) Actual() string {
+	return e.actual
+}
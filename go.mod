@@ -2,3 +2,8 @@ module github.com/gopi-frame/cache
 
 go 1.22.2
 
+require (
+	github.com/go-viper/mapstructure/v2 v2.2.1
+	github.com/golang/snappy v1.0.0
+	github.com/klauspost/compress v1.18.0
+)
@@ -0,0 +1,135 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gopi-frame/exception"
+)
+
+var ErrHashNotSupported = errors.New("cache store does not support hash")
+
+// HashStore is implemented by stores which can keep a value as a hash of fields, such as the redis driver.
+type HashStore interface {
+	HGet(key string, field string) (string, error)
+	HSet(key string, values map[string]string, expire time.Duration) error
+	HGetAll(key string) (map[string]string, error)
+	HDel(key string, fields ...string) error
+}
+
+type hashField struct {
+	name  string
+	index []int
+}
+
+// Hash maps struct type T to a hash, every exported field is stored as a JSON encoded hash field.
+// The field name is taken from the `cache` tag, then the `json` tag, then the struct field name.
+// Fields tagged with `cache:"-"` are skipped.
+type Hash[T any] struct {
+	store  HashStore
+	fields []hashField
+	byName map[string]hashField
+}
+
+// NewHash creates a new typed hash on store.
+func NewHash[T any](store HashStore) (*Hash[T], error) {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if typ.Kind() != reflect.Struct {
+		return nil, exception.NewArgumentException("T", typ.String(), "hash type must be a struct")
+	}
+	h := &Hash[T]{
+		store:  store,
+		byName: make(map[string]hashField),
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name := f.Name
+		if tag, ok := f.Tag.Lookup("cache"); ok {
+			name, _, _ = strings.Cut(tag, ",")
+		} else if tag, ok := f.Tag.Lookup("json"); ok {
+			if n, _, _ := strings.Cut(tag, ","); n != "" {
+				name = n
+			}
+		}
+		if name == "-" || name == "" {
+			continue
+		}
+		field := hashField{name: name, index: f.Index}
+		h.fields = append(h.fields, field)
+		h.byName[name] = field
+	}
+	return h, nil
+}
+
+// Hash returns the typed hash for the underlying store.
+// It returns [ErrHashNotSupported] if the store does not implement [HashStore].
+func (c *Cache[T]) Hash() (*Hash[T], error) {
+	store, ok := c.Cache.(HashStore)
+	if !ok {
+		return nil, ErrHashNotSupported
+	}
+	return NewHash[T](store)
+}
+
+// Get reads all fields of the hash into a T.
+func (h *Hash[T]) Get(key string) (T, error) {
+	var value T
+	values, err := h.store.HGetAll(key)
+	if err != nil {
+		return value, err
+	}
+	rv := reflect.ValueOf(&value).Elem()
+	for _, field := range h.fields {
+		raw, ok := values[field.name]
+		if !ok {
+			continue
+		}
+		if err := json.Unmarshal([]byte(raw), rv.FieldByIndex(field.index).Addr().Interface()); err != nil {
+			return *new(T), err
+		}
+	}
+	return value, nil
+}
+
+// Set writes all fields of value.
+func (h *Hash[T]) Set(key string, value T, expire time.Duration) error {
+	return h.write(key, value, expire, h.fields)
+}
+
+// SetFields writes only the named fields of value, the other fields of the hash are kept.
+func (h *Hash[T]) SetFields(key string, value T, expire time.Duration, fields ...string) error {
+	list := make([]hashField, 0, len(fields))
+	for _, name := range fields {
+		field, ok := h.byName[name]
+		if !ok {
+			return exception.NewArgumentException("fields", name, fmt.Sprintf("unknown field \"%s\"", name))
+		}
+		list = append(list, field)
+	}
+	return h.write(key, value, expire, list)
+}
+
+// DeleteFields deletes the named fields of the hash.
+func (h *Hash[T]) DeleteFields(key string, fields ...string) error {
+	return h.store.HDel(key, fields...)
+}
+
+func (h *Hash[T]) write(key string, value T, expire time.Duration, fields []hashField) error {
+	rv := reflect.ValueOf(value)
+	values := make(map[string]string, len(fields))
+	for _, field := range fields {
+		bs, err := json.Marshal(rv.FieldByIndex(field.index).Interface())
+		if err != nil {
+			return err
+		}
+		values[field.name] = string(bs)
+	}
+	return h.store.HSet(key, values, expire)
+}
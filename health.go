@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gopi-frame/contract/cache"
+)
+
+// HealthChecker is implemented by stores which can check whether their backend is reachable.
+type HealthChecker interface {
+	// CheckHealth returns an error if the store is not usable.
+	CheckHealth(ctx context.Context) error
+}
+
+// CheckHealth checks the health of store.
+// Stores which do not implement [HealthChecker] are considered healthy.
+func CheckHealth(ctx context.Context, store cache.Cache) error {
+	if checker, ok := store.(HealthChecker); ok {
+		return checker.CheckHealth(ctx)
+	}
+	return nil
+}
+
+// StoreHealth is the health of a store.
+type StoreHealth struct {
+	// Name is the store name.
+	Name string `json:"name"`
+	// Healthy reports whether the check succeeded.
+	Healthy bool `json:"healthy"`
+	// Error is the error message of the failed check.
+	Error string `json:"error,omitempty"`
+	// Latency is the time taken by the check.
+	Latency time.Duration `json:"latency"`
+}
+
+// HealthReport is the health of all stores of a [CacheManager].
+type HealthReport struct {
+	// Healthy reports whether all stores are healthy.
+	Healthy bool `json:"healthy"`
+	// Stores are the health of every store, sorted by name.
+	Stores []StoreHealth `json:"stores"`
+}
+
+// Health checks all stores concurrently and reports their status and latency.
+func (c *CacheManager) Health(ctx context.Context) HealthReport {
+	c.stores.RLock()
+	names := c.stores.Keys()
+	stores := make([]cache.Cache, len(names))
+	for i, name := range names {
+		stores[i], _ = c.stores.Get(name)
+	}
+	c.stores.RUnlock()
+	report := HealthReport{Healthy: true, Stores: make([]StoreHealth, len(names))}
+	var wg sync.WaitGroup
+	for i := range names {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			start := time.Now()
+			err := CheckHealth(ctx, stores[i])
+			health := StoreHealth{Name: names[i], Healthy: err == nil, Latency: time.Since(start)}
+			if err != nil {
+				health.Error = err.Error()
+			}
+			report.Stores[i] = health
+		}(i)
+	}
+	wg.Wait()
+	sort.Slice(report.Stores, func(i, j int) bool {
+		return report.Stores[i].Name < report.Stores[j].Name
+	})
+	for _, health := range report.Stores {
+		if !health.Healthy {
+			report.Healthy = false
+		}
+	}
+	return report
+}
@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type unhealthyStore struct {
+	*mapStore
+}
+
+func (s unhealthyStore) CheckHealth(_ context.Context) error {
+	return errors.New("unreachable")
+}
+
+func TestCacheManager_Health(t *testing.T) {
+	t.Run("healthy", func(t *testing.T) {
+		manager := NewCacheManager()
+		manager.AddStore("store1", newMapStore())
+		manager.AddStore("store2", NewDeferCache("test-map", map[string]any{}))
+		report := manager.Health(context.Background())
+		assert.True(t, report.Healthy)
+		if assert.Len(t, report.Stores, 2) {
+			assert.Equal(t, "store1", report.Stores[0].Name)
+			assert.Equal(t, "store2", report.Stores[1].Name)
+			assert.True(t, report.Stores[0].Healthy)
+			assert.Empty(t, report.Stores[0].Error)
+		}
+	})
+
+	t.Run("unhealthy", func(t *testing.T) {
+		manager := NewCacheManager()
+		manager.AddStore("store1", newMapStore())
+		manager.AddStore("store2", unhealthyStore{newMapStore()})
+		manager.AddStore("store3", NewDeferCache("test-map", map[string]any{"fail": true}))
+		report := manager.Health(context.Background())
+		assert.False(t, report.Healthy)
+		if assert.Len(t, report.Stores, 3) {
+			assert.True(t, report.Stores[0].Healthy)
+			assert.False(t, report.Stores[1].Healthy)
+			assert.Equal(t, "unreachable", report.Stores[1].Error)
+			assert.False(t, report.Stores[2].Healthy)
+			assert.Equal(t, ErrCacheNotFound.Error(), report.Stores[2].Error)
+		}
+	})
+
+	t.Run("slow open", func(t *testing.T) {
+		block := make(chan struct{})
+		defer close(block)
+		manager := NewCacheManager()
+		manager.AddStore("store1", NewDeferCache("test-map", map[string]any{"block": block}))
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		report := manager.Health(ctx)
+		assert.False(t, report.Healthy)
+		if assert.Len(t, report.Stores, 1) {
+			assert.Equal(t, context.DeadlineExceeded.Error(), report.Stores[0].Error)
+		}
+	})
+}
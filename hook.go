@@ -0,0 +1,149 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/gopi-frame/contract/cache"
+)
+
+// Op describes a cache operation passed to hooks.
+type Op struct {
+	// Name is the operation name, such as [OpGet].
+	Name string
+	// Store is the store name.
+	Store string
+	// Key is the cache key, it is empty for Clear.
+	Key string
+	// Hit reports whether Get, Load or Has found the value, it is set before AfterOp.
+	Hit bool
+	// Err is the error returned by the operation, it is set before AfterOp.
+	Err error
+	// Duration is the time taken by the operation, it is set before AfterOp.
+	Duration time.Duration
+}
+
+// Hook observes cache operations.
+type Hook interface {
+	// BeforeOp is called before the operation, the returned context is passed to AfterOp.
+	BeforeOp(ctx context.Context, op *Op) context.Context
+	// AfterOp is called after the operation with its result.
+	AfterOp(ctx context.Context, op *Op)
+}
+
+// ContextBinder is implemented by stores which pass a context to their hooks.
+type ContextBinder interface {
+	WithContext(ctx context.Context) cache.Cache
+}
+
+// WithContext binds ctx to store if it supports it, so hooks can relate its operations to the caller,
+// such as to the current trace. Other stores are returned unchanged.
+func WithContext(ctx context.Context, store cache.Cache) cache.Cache {
+	if binder, ok := store.(ContextBinder); ok {
+		return binder.WithContext(ctx)
+	}
+	return store
+}
+
+// HookCache runs hooks around the operations of a store.
+// Hooks are called in order before the operation and in reverse order after it.
+type HookCache struct {
+	Forwarder
+	name  string
+	hooks []Hook
+	ctx   context.Context
+}
+
+// NewHookCache creates a cache which runs hooks around the operations of store.
+func NewHookCache(name string, store cache.Cache, hooks ...Hook) *HookCache {
+	return &HookCache{
+		Forwarder: Forwarder{Next: store},
+		name:      name,
+		hooks:     hooks,
+		ctx:       context.Background(),
+	}
+}
+
+// HookMiddleware returns a middleware which wraps stores with [NewHookCache].
+func HookMiddleware(name string, hooks ...Hook) Middleware {
+	return func(next cache.Cache) cache.Cache {
+		return NewHookCache(name, next, hooks...)
+	}
+}
+
+// WithContext returns a copy of the cache which passes ctx to the hooks.
+func (c *HookCache) WithContext(ctx context.Context) cache.Cache {
+	clone := *c
+	clone.ctx = ctx
+	return &clone
+}
+
+func (c *HookCache) run(name string, key string, fn func(op *Op)) {
+	op := &Op{Name: name, Store: c.name, Key: key}
+	contexts := make([]context.Context, len(c.hooks))
+	ctx := c.ctx
+	for i, hook := range c.hooks {
+		ctx = hook.BeforeOp(ctx, op)
+		contexts[i] = ctx
+	}
+	start := time.Now()
+	fn(op)
+	op.Duration = time.Since(start)
+	for i := len(c.hooks) - 1; i >= 0; i-- {
+		c.hooks[i].AfterOp(contexts[i], op)
+	}
+}
+
+func (c *HookCache) Get(key string) (value string, err error) {
+	c.run(OpGet, key, func(op *Op) {
+		value, err = c.Next.Get(key)
+		op.Hit = err == nil
+		op.Err = err
+	})
+	return
+}
+
+func (c *HookCache) Set(key string, value string, expire time.Duration) (err error) {
+	c.run(OpSet, key, func(op *Op) {
+		err = c.Next.Set(key, value, expire)
+		op.Err = err
+	})
+	return
+}
+
+func (c *HookCache) Load(key string, loader func() (string, error), expire time.Duration) (value string, err error) {
+	c.run(OpLoad, key, func(op *Op) {
+		var loaded bool
+		value, err = c.Next.Load(key, func() (string, error) {
+			loaded = true
+			return loader()
+		}, expire)
+		op.Hit = !loaded && err == nil
+		op.Err = err
+	})
+	return
+}
+
+func (c *HookCache) Delete(key string) (err error) {
+	c.run(OpDelete, key, func(op *Op) {
+		err = c.Next.Delete(key)
+		op.Err = err
+	})
+	return
+}
+
+func (c *HookCache) Has(key string) (ok bool) {
+	c.run(OpHas, key, func(op *Op) {
+		ok = c.Next.Has(key)
+		op.Hit = ok
+	})
+	return
+}
+
+func (c *HookCache) Clear() (err error) {
+	c.run(OpClear, "", func(op *Op) {
+		err = c.Next.Clear()
+		op.Err = err
+	})
+	return
+}
@@ -0,0 +1,166 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type ctxKey string
+
+type recordHook struct {
+	name  string
+	calls *[]string
+	ops   []Op
+}
+
+func (h *recordHook) BeforeOp(ctx context.Context, op *Op) context.Context {
+	*h.calls = append(*h.calls, "before "+h.name)
+	return context.WithValue(ctx, ctxKey(h.name), op.Name)
+}
+
+func (h *recordHook) AfterOp(ctx context.Context, op *Op) {
+	*h.calls = append(*h.calls, "after "+h.name)
+	if ctx.Value(ctxKey(h.name)) == op.Name {
+		h.ops = append(h.ops, *op)
+	}
+}
+
+type fakeSpan struct {
+	name  string
+	attrs map[string]any
+	err   error
+	ended bool
+}
+
+func (s *fakeSpan) SetAttribute(key string, value any) { s.attrs[key] = value }
+func (s *fakeSpan) RecordError(err error)              { s.err = err }
+func (s *fakeSpan) End()                               { s.ended = true }
+
+type fakeTracer struct {
+	spans []*fakeSpan
+}
+
+func (t *fakeTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	span := &fakeSpan{name: name, attrs: make(map[string]any)}
+	t.spans = append(t.spans, span)
+	return ctx, span
+}
+
+func TestHookCache(t *testing.T) {
+	t.Run("order and result", func(t *testing.T) {
+		var calls []string
+		first, second := &recordHook{name: "first", calls: &calls}, &recordHook{name: "second", calls: &calls}
+		c := NewHookCache("local", newMapStore(), first, second)
+		assert.NoError(t, c.Set("key", "value", 0))
+		assert.Equal(t, []string{"before first", "before second", "after second", "after first"}, calls)
+		_, _ = c.Get("key")
+		_, _ = c.Get("missing")
+		_, _ = c.Load("loaded", func() (string, error) { return "value", nil }, 0)
+		assert.True(t, c.Has("loaded"))
+		assert.NoError(t, c.Clear())
+		if assert.Len(t, first.ops, 6) {
+			assert.Equal(t, Op{Name: OpSet, Store: "local", Key: "key", Duration: first.ops[0].Duration}, first.ops[0])
+			assert.True(t, first.ops[1].Hit)
+			assert.False(t, first.ops[2].Hit)
+			assert.ErrorIs(t, first.ops[2].Err, ErrCacheNotFound)
+			assert.False(t, first.ops[3].Hit)
+			assert.True(t, first.ops[4].Hit)
+			assert.Equal(t, OpClear, first.ops[5].Name)
+			assert.Empty(t, first.ops[5].Key)
+		}
+	})
+
+	t.Run("with context", func(t *testing.T) {
+		var got context.Context
+		hook := hookFunc(func(ctx context.Context, op *Op) { got = ctx })
+		c := NewHookCache("local", newMapStore(), hook)
+		ctx := context.WithValue(context.Background(), ctxKey("request"), "id")
+		_, _ = WithContext(ctx, c).Get("key")
+		assert.Equal(t, "id", got.Value(ctxKey("request")))
+		_, _ = c.Get("key")
+		assert.Nil(t, got.Value(ctxKey("request")))
+		store := newMapStore()
+		assert.Same(t, store, WithContext(ctx, store))
+	})
+
+	t.Run("manager", func(t *testing.T) {
+		var calls []string
+		hook := &recordHook{name: "hook", calls: &calls}
+		manager := NewCacheManager()
+		manager.AddStore("local", newMapStore())
+		manager.SetDefaultStore("local")
+		manager.AddHook(hook)
+		assert.NoError(t, manager.Set("key", "value", 0))
+		_, _ = manager.GetStore("local").Get("key")
+		if assert.Len(t, hook.ops, 2) {
+			assert.Equal(t, "local", hook.ops[0].Store)
+			assert.True(t, hook.ops[1].Hit)
+		}
+
+		// stores are wrapped once and the added store can be reached
+		assert.Same(t, manager.GetStore("local"), manager.GetStore("local"))
+		store := newMapStore()
+		manager.AddStore("other", store)
+		assert.Same(t, store, Unwrap(manager.GetStore("other")))
+		found, ok := As[*mapStore](manager.GetStore("other"))
+		assert.True(t, ok)
+		assert.Same(t, store, found)
+		_, ok = As[HashStore](manager.GetStore("other"))
+		assert.False(t, ok)
+	})
+}
+
+type hookFunc func(ctx context.Context, op *Op)
+
+func (f hookFunc) BeforeOp(ctx context.Context, _ *Op) context.Context { return ctx }
+func (f hookFunc) AfterOp(ctx context.Context, op *Op)                 { f(ctx, op) }
+
+func TestTracingHook(t *testing.T) {
+	tracer := new(fakeTracer)
+	c := NewHookCache("redis", newMapStore(), NewTracingHook(tracer, false))
+	_, _ = c.Get("key")
+	_, _ = c.Load("key", func() (string, error) { return "", errors.New("failed") }, 0)
+	if assert.Len(t, tracer.spans, 2) {
+		span := tracer.spans[0]
+		assert.Equal(t, "cache.get", span.name)
+		assert.Equal(t, "redis", span.attrs["cache.store"])
+		assert.Equal(t, false, span.attrs["cache.hit"])
+		assert.NotContains(t, span.attrs, "cache.key")
+		assert.Nil(t, span.err)
+		assert.True(t, span.ended)
+		assert.EqualError(t, tracer.spans[1].err, "failed")
+	}
+
+	tracer = new(fakeTracer)
+	c = NewHookCache("redis", newMapStore(), NewTracingHook(tracer, true))
+	_ = c.Set("key", "value", 0)
+	assert.Equal(t, "key", tracer.spans[0].attrs["cache.key"])
+	assert.NotContains(t, tracer.spans[0].attrs, "cache.hit")
+}
+
+func TestSlogHook(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger := slog.New(slog.NewTextHandler(buf, nil))
+	c := NewHookCache("local", newMapStore(), NewSlogHook(logger, time.Hour))
+	_, _ = c.Get("missing")
+	assert.Empty(t, buf.String())
+	_, _ = c.Load("key", func() (string, error) { return "", errors.New("failed") }, 0)
+	assert.Contains(t, buf.String(), "level=ERROR")
+	assert.Contains(t, buf.String(), "op=load")
+	assert.Contains(t, buf.String(), "error=failed")
+
+	buf.Reset()
+	c = NewHookCache("local", newMapStore(), NewSlogHook(logger, time.Nanosecond))
+	_, _ = c.Load("key", func() (string, error) {
+		time.Sleep(time.Millisecond)
+		return "value", nil
+	}, 0)
+	assert.Contains(t, buf.String(), "level=WARN")
+	assert.Contains(t, buf.String(), "slow cache operation")
+}
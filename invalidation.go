@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/gopi-frame/contract/cache"
+)
+
+// Invalidation is the message broadcast to peers when keys are removed from a cache.
+type Invalidation struct {
+	// Source is the id of the publisher, peers use it to ignore their own messages.
+	Source string `json:"source"`
+	// Keys are the invalidated keys.
+	Keys []string `json:"keys,omitempty"`
+	// All reports whether the whole cache was cleared.
+	All bool `json:"all,omitempty"`
+}
+
+// InvalidationBus broadcasts invalidations between cache instances.
+type InvalidationBus interface {
+	// Publish broadcasts the invalidation to all subscribers.
+	Publish(ctx context.Context, message Invalidation) error
+	// Subscribe calls handler for every received invalidation until ctx is done or the bus is closed.
+	// It returns once the subscription is established.
+	Subscribe(ctx context.Context, handler func(message Invalidation)) error
+	// Close stops all subscriptions.
+	Close() error
+}
+
+// InvalidatingCache wraps a local cache, publishes its deletions to an [InvalidationBus]
+// and evicts keys published by peers.
+type InvalidatingCache struct {
+	cache.Cache
+
+	id     string
+	bus    InvalidationBus
+	cancel context.CancelFunc
+}
+
+// NewInvalidatingCache creates a cache which keeps store in sync with its peers through bus.
+func NewInvalidatingCache(store cache.Cache, bus InvalidationBus) (*InvalidatingCache, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &InvalidatingCache{
+		Cache:  store,
+		id:     hex.EncodeToString(id),
+		bus:    bus,
+		cancel: cancel,
+	}
+	if err := bus.Subscribe(ctx, c.handle); err != nil {
+		cancel()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *InvalidatingCache) handle(message Invalidation) {
+	if message.Source == c.id {
+		return
+	}
+	if message.All {
+		_ = c.Cache.Clear()
+		return
+	}
+	for _, key := range message.Keys {
+		_ = c.Cache.Delete(key)
+	}
+}
+
+func (c *InvalidatingCache) publish(message Invalidation) error {
+	message.Source = c.id
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return c.bus.Publish(ctx, message)
+}
+
+// ID returns the id used as the source of published invalidations.
+func (c *InvalidatingCache) ID() string {
+	return c.id
+}
+
+func (c *InvalidatingCache) Delete(key string) error {
+	if err := c.Cache.Delete(key); err != nil {
+		return err
+	}
+	return c.publish(Invalidation{Keys: []string{key}})
+}
+
+func (c *InvalidatingCache) Clear() error {
+	if err := c.Cache.Clear(); err != nil {
+		return err
+	}
+	return c.publish(Invalidation{All: true})
+}
+
+// CheckHealth checks the health of the wrapped cache.
+func (c *InvalidatingCache) CheckHealth(ctx context.Context) error {
+	return CheckHealth(ctx, c.Cache)
+}
+
+// Close stops receiving invalidations, the bus itself is left open.
+func (c *InvalidatingCache) Close() error {
+	c.cancel()
+	return nil
+}
@@ -0,0 +1,101 @@
+package cache
+
+import (
+	"math/rand/v2"
+	"time"
+
+	"github.com/gopi-frame/contract/cache"
+	"github.com/gopi-frame/exception"
+)
+
+func init() {
+	RegisterMiddleware("jitter", func(_ string, options map[string]any) (Middleware, error) {
+		config := new(JitterConfig)
+		if err := DecodeConfig(options, config); err != nil {
+			return nil, err
+		}
+		return JitterMiddleware(config)
+	})
+}
+
+// JitterTTL shortens ttl by a random fraction of up to percent, such as 0.1 for up to 10%,
+// so values set together do not expire together. Non-positive ttls are returned as they are.
+func JitterTTL(ttl time.Duration, percent float64) time.Duration {
+	if ttl <= 0 || percent <= 0 {
+		return ttl
+	}
+	return ttl - time.Duration(float64(ttl)*percent*rand.Float64())
+}
+
+// JitterConfig is the TTL jitter config.
+type JitterConfig struct {
+	// Percent is the maximum fraction by which expire times are shortened, between 0 and 1, such as 0.1 for 10%.
+	Percent float64 `json:"percent" yaml:"percent" toml:"percent" mapstructure:"percent"`
+	// TTL is the expire time jittered when no expire time is given.
+	// If not set, the default expire time of the store is used without jitter.
+	TTL time.Duration `json:"ttl" yaml:"ttl" toml:"ttl" mapstructure:"ttl"`
+}
+
+func (c *JitterConfig) validate() error {
+	if c.Percent < 0 || c.Percent > 1 {
+		return exception.NewArgumentException("percent", c.Percent, "percent must be between 0 and 1")
+	}
+	return nil
+}
+
+// JitterCache applies [JitterTTL] to the expire times of Set and Load.
+type JitterCache struct {
+	Forwarder
+	percent float64
+	ttl     time.Duration
+}
+
+// NewJitterCache creates a cache which jitters the expire times of store.
+func NewJitterCache(store cache.Cache, config *JitterConfig) (*JitterCache, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	return &JitterCache{
+		Forwarder: Forwarder{Next: store},
+		percent:   config.Percent,
+		ttl:       config.TTL,
+	}, nil
+}
+
+// JitterMiddleware returns a middleware which wraps stores with [NewJitterCache].
+func JitterMiddleware(config *JitterConfig) (Middleware, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	return func(next cache.Cache) cache.Cache {
+		c, _ := NewJitterCache(next, config)
+		return c
+	}, nil
+}
+
+func (c *JitterCache) jitter(expire time.Duration) time.Duration {
+	if expire <= 0 {
+		expire = c.ttl
+	}
+	return JitterTTL(expire, c.percent)
+}
+
+func (c *JitterCache) Set(key string, value string, expire time.Duration) error {
+	return c.Next.Set(key, value, c.jitter(expire))
+}
+
+func (c *JitterCache) Load(key string, loader func() (string, error), expire time.Duration) (string, error) {
+	return c.Next.Load(key, loader, c.jitter(expire))
+}
+
+// WithJitter jitters the expire times of the cache, see [JitterCache].
+func WithJitter[T any](config *JitterConfig) OptionFunc[T] {
+	return func(c *Cache[T]) error {
+		store, err := NewJitterCache(c.Cache, config)
+		if err != nil {
+			return err
+		}
+		c.Cache = store
+		return nil
+	}
+}
@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJitterTTL(t *testing.T) {
+	t.Run("within percent", func(t *testing.T) {
+		spread := map[time.Duration]bool{}
+		for i := 0; i < 100; i++ {
+			ttl := JitterTTL(time.Hour, 0.1)
+			assert.LessOrEqual(t, ttl, time.Hour)
+			assert.GreaterOrEqual(t, ttl, 54*time.Minute)
+			spread[ttl] = true
+		}
+		assert.Greater(t, len(spread), 1)
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		assert.Equal(t, time.Hour, JitterTTL(time.Hour, 0))
+		assert.Equal(t, time.Duration(0), JitterTTL(0, 0.1))
+	})
+}
+
+func TestJitterCache(t *testing.T) {
+	t.Run("set and load", func(t *testing.T) {
+		store := newMapStore()
+		c, err := NewJitterCache(store, &JitterConfig{Percent: 0.5})
+		if err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		for i := 0; i < 20; i++ {
+			assert.NoError(t, c.Set("key", "value", time.Hour))
+			assert.True(t, store.expire["key"].Before(time.Now().Add(time.Hour+time.Second)))
+			assert.True(t, store.expire["key"].After(time.Now().Add(29*time.Minute)))
+		}
+		_, err = c.Load("loaded", func() (string, error) { return "value", nil }, time.Hour)
+		assert.NoError(t, err)
+		assert.True(t, store.expire["loaded"].Before(time.Now().Add(time.Hour+time.Second)))
+	})
+
+	t.Run("default ttl", func(t *testing.T) {
+		store := newMapStore()
+		c, _ := NewJitterCache(store, &JitterConfig{Percent: 0.1, TTL: time.Minute})
+		assert.NoError(t, c.Set("key", "value", 0))
+		assert.WithinDuration(t, time.Now().Add(time.Minute), store.expire["key"], 7*time.Second)
+
+		c, _ = NewJitterCache(store, &JitterConfig{Percent: 0.1})
+		assert.NoError(t, c.Set("key", "value", 0))
+		assert.True(t, store.expire["key"].IsZero())
+	})
+
+	t.Run("invalid percent", func(t *testing.T) {
+		_, err := NewJitterCache(newMapStore(), &JitterConfig{Percent: 1.5})
+		assert.Error(t, err)
+		_, err = OpenMiddleware("jitter", "default", map[string]any{"percent": -0.1})
+		assert.Error(t, err)
+	})
+}
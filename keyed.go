@@ -0,0 +1,190 @@
+package cache
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gopi-frame/contract/cache"
+	"github.com/gopi-frame/exception"
+)
+
+// KeyEncoder builds the string key of a typed key.
+type KeyEncoder[K any] interface {
+	EncodeKey(key K) (string, error)
+}
+
+// KeyEncoderFunc is a function [KeyEncoder].
+type KeyEncoderFunc[K any] func(key K) (string, error)
+
+func (f KeyEncoderFunc[K]) EncodeKey(key K) (string, error) {
+	return f(key)
+}
+
+var keyEscaper = strings.NewReplacer(`\`, `\\`, `:`, `\:`)
+
+// StructKeyEncoder builds keys such as "user:42:en" from the prefix and the exported fields of struct K, in field order.
+// Fields tagged with `cache:"-"` are skipped, and colons in field values are escaped so keys cannot collide.
+// Field values are formatted as by [DefaultKeyEncoder].
+func StructKeyEncoder[K any](prefix string) (KeyEncoder[K], error) {
+	typ := reflect.TypeOf((*K)(nil)).Elem()
+	if typ.Kind() != reflect.Struct {
+		return nil, exception.NewArgumentException("K", typ.String(), "key type must be a struct")
+	}
+	var fields [][]int
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if !f.IsExported() || f.Tag.Get("cache") == "-" {
+			continue
+		}
+		if _, err := formatKeyValue(f.Type); err != nil {
+			return nil, exception.NewArgumentException("K", typ.String(), fmt.Sprintf("field %s: %s", f.Name, err))
+		}
+		fields = append(fields, f.Index)
+	}
+	return KeyEncoderFunc[K](func(key K) (string, error) {
+		rv := reflect.ValueOf(key)
+		parts := make([]string, 0, len(fields)+1)
+		if prefix != "" {
+			parts = append(parts, prefix)
+		}
+		for _, index := range fields {
+			field := rv.FieldByIndex(index)
+			format, _ := formatKeyValue(field.Type())
+			parts = append(parts, keyEscaper.Replace(format(field)))
+		}
+		return strings.Join(parts, ":"), nil
+	}), nil
+}
+
+// DefaultKeyEncoder returns the key encoder used by [NewKeyed] if none is given:
+// strings are used as they are, [fmt.Stringer] keys by their String method, numbers and booleans are formatted,
+// and structs are encoded by [StructKeyEncoder] prefixed with the type name.
+func DefaultKeyEncoder[K any]() (KeyEncoder[K], error) {
+	typ := reflect.TypeOf((*K)(nil)).Elem()
+	if typ.Implements(reflect.TypeOf((*fmt.Stringer)(nil)).Elem()) {
+		return KeyEncoderFunc[K](func(key K) (string, error) {
+			if rv := reflect.ValueOf(key); !rv.IsValid() || (rv.Kind() == reflect.Pointer && rv.IsNil()) {
+				return "", exception.NewEmptyArgumentException("key")
+			}
+			return any(key).(fmt.Stringer).String(), nil
+		}), nil
+	}
+	if typ.Kind() == reflect.Struct {
+		return StructKeyEncoder[K](typ.Name())
+	}
+	format, err := formatKeyValue(typ)
+	if err != nil {
+		return nil, exception.NewArgumentException("K", typ.String(), err.Error())
+	}
+	return KeyEncoderFunc[K](func(key K) (string, error) {
+		return format(reflect.ValueOf(key)), nil
+	}), nil
+}
+
+func formatKeyValue(typ reflect.Type) (func(v reflect.Value) string, error) {
+	if typ.Implements(reflect.TypeOf((*fmt.Stringer)(nil)).Elem()) && typ.Kind() != reflect.Pointer && typ.Kind() != reflect.Interface {
+		return func(v reflect.Value) string {
+			return v.Interface().(fmt.Stringer).String()
+		}, nil
+	}
+	switch typ.Kind() {
+	case reflect.String:
+		return func(v reflect.Value) string { return v.String() }, nil
+	case reflect.Bool:
+		return func(v reflect.Value) string { return strconv.FormatBool(v.Bool()) }, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return func(v reflect.Value) string { return strconv.FormatInt(v.Int(), 10) }, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return func(v reflect.Value) string { return strconv.FormatUint(v.Uint(), 10) }, nil
+	case reflect.Float32, reflect.Float64:
+		return func(v reflect.Value) string { return strconv.FormatFloat(v.Float(), 'g', -1, typ.Bits()) }, nil
+	default:
+		return nil, fmt.Errorf("unsupported key kind %s", typ.Kind())
+	}
+}
+
+// KeyedCache is a typed cache with typed keys, such as
+//
+//	type UserKey struct {
+//		ID     int
+//		Locale string
+//	}
+//
+//	users, err := cache.NewKeyed[UserKey, User](store, nil)
+//	user, err := users.Get(UserKey{ID: 42, Locale: "en"}) // key "UserKey:42:en"
+type KeyedCache[K any, V any] struct {
+	cache *Cache[V]
+	keys  KeyEncoder[K]
+}
+
+// NewKeyed creates a keyed cache on store, the [DefaultKeyEncoder] is used if keys is nil.
+func NewKeyed[K any, V any](store cache.Cache, keys KeyEncoder[K], opts ...Option[V]) (*KeyedCache[K, V], error) {
+	if keys == nil {
+		var err error
+		if keys, err = DefaultKeyEncoder[K](); err != nil {
+			return nil, err
+		}
+	}
+	c, err := New[V](store, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyedCache[K, V]{cache: c, keys: keys}, nil
+}
+
+// Typed returns the typed cache with string keys.
+func (c *KeyedCache[K, V]) Typed() *Cache[V] {
+	return c.cache
+}
+
+// Key returns the string key of key.
+func (c *KeyedCache[K, V]) Key(key K) (string, error) {
+	return c.keys.EncodeKey(key)
+}
+
+func (c *KeyedCache[K, V]) Get(key K) (V, error) {
+	k, err := c.keys.EncodeKey(key)
+	if err != nil {
+		return *new(V), err
+	}
+	return c.cache.Get(k)
+}
+
+func (c *KeyedCache[K, V]) Set(key K, value V, expire time.Duration) error {
+	k, err := c.keys.EncodeKey(key)
+	if err != nil {
+		return err
+	}
+	return c.cache.Set(k, value, expire)
+}
+
+func (c *KeyedCache[K, V]) Load(key K, loader func() (V, error), expire time.Duration) (V, error) {
+	k, err := c.keys.EncodeKey(key)
+	if err != nil {
+		return *new(V), err
+	}
+	return c.cache.Load(k, loader, expire)
+}
+
+func (c *KeyedCache[K, V]) Delete(key K) error {
+	k, err := c.keys.EncodeKey(key)
+	if err != nil {
+		return err
+	}
+	return c.cache.Delete(k)
+}
+
+func (c *KeyedCache[K, V]) Has(key K) bool {
+	k, err := c.keys.EncodeKey(key)
+	if err != nil {
+		return false
+	}
+	return c.cache.Has(k)
+}
+
+func (c *KeyedCache[K, V]) Clear() error {
+	return c.cache.Clear()
+}
@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type userKey struct {
+	ID     int
+	Locale string
+	Debug  bool `cache:"-"`
+	secret string
+}
+
+type sku int
+
+func (s sku) String() string {
+	return "sku-" + string(rune('a'+int(s)))
+}
+
+func TestKeyedCache(t *testing.T) {
+	t.Run("struct key", func(t *testing.T) {
+		store := newMapStore()
+		c, err := NewKeyed[userKey, string](store, nil)
+		if err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		key := userKey{ID: 42, Locale: "en", Debug: true, secret: "x"}
+		assert.NoError(t, c.Set(key, "Gopi", 0))
+		assert.True(t, store.Has("userKey:42:en"))
+		assert.True(t, c.Has(userKey{ID: 42, Locale: "en"}))
+		value, err := c.Get(userKey{ID: 42, Locale: "en"})
+		assert.NoError(t, err)
+		assert.Equal(t, "Gopi", value)
+		value, err = c.Load(userKey{ID: 1, Locale: "fr"}, func() (string, error) { return "Frame", nil }, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, "Frame", value)
+		assert.NoError(t, c.Delete(key))
+		assert.False(t, c.Has(key))
+	})
+
+	t.Run("escape", func(t *testing.T) {
+		encoder, err := StructKeyEncoder[userKey]("user")
+		if err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		a, _ := encoder.EncodeKey(userKey{ID: 1, Locale: "a:b"})
+		b, _ := encoder.EncodeKey(userKey{ID: 1, Locale: `a\:b`})
+		assert.Equal(t, `user:1:a\:b`, a)
+		assert.NotEqual(t, a, b)
+	})
+
+	t.Run("scalar keys", func(t *testing.T) {
+		store := newMapStore()
+		ints, _ := NewKeyed[int64, string](store, nil)
+		assert.NoError(t, ints.Set(-7, "int", 0))
+		assert.True(t, store.Has("-7"))
+		strs, _ := NewKeyed[string, string](store, nil)
+		assert.NoError(t, strs.Set("plain", "string", 0))
+		assert.True(t, store.Has("plain"))
+		stringers, _ := NewKeyed[sku, string](store, nil)
+		assert.NoError(t, stringers.Set(sku(1), "stringer", 0))
+		assert.True(t, store.Has("sku-b"))
+	})
+
+	t.Run("custom encoder", func(t *testing.T) {
+		store := newMapStore()
+		c, _ := NewKeyed[int, string](store, KeyEncoderFunc[int](func(id int) (string, error) {
+			if id <= 0 {
+				return "", errors.New("invalid id")
+			}
+			return "order:" + string(rune('0'+id)), nil
+		}))
+		assert.NoError(t, c.Set(3, "order", 0))
+		assert.True(t, store.Has("order:3"))
+		assert.Error(t, c.Set(0, "order", 0))
+		_, err := c.Get(-1)
+		assert.Error(t, err)
+		assert.False(t, c.Has(-1))
+	})
+
+	t.Run("unsupported key", func(t *testing.T) {
+		_, err := NewKeyed[[]string, string](newMapStore(), nil)
+		assert.Error(t, err)
+		_, err = NewKeyed[struct{ Tags []string }, string](newMapStore(), nil)
+		assert.Error(t, err)
+	})
+}
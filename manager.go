@@ -2,36 +2,103 @@ package cache
 
 import (
 	"sync"
+	"time"
 
 	"github.com/gopi-frame/collection/kv"
 	"github.com/gopi-frame/contract/cache"
 )
 
 // CacheManager is a cache manager.
+// It implements the cache API by delegating to the default store, which is resolved on every call,
+// so the default store and the stores themselves can be switched at runtime.
 type CacheManager struct {
-	once sync.Once
-	cache.Cache
-
+	mu           sync.RWMutex
 	defaultStore string
+	hooks        []Hook
 	stores       *kv.Map[string, cache.Cache]
+	// resolved are the stores returned by TryStore, the stores wrapped with the hooks if any.
+	// It is guarded by the lock of stores.
+	resolved map[string]cache.Cache
 }
 
 func NewCacheManager() *CacheManager {
 	return &CacheManager{
-		stores: kv.NewMap[string, cache.Cache](),
+		stores:   kv.NewMap[string, cache.Cache](),
+		resolved: make(map[string]cache.Cache),
+	}
+}
+
+// set sets the store, the caller must hold the lock of stores.
+func (c *CacheManager) set(name string, store cache.Cache) {
+	c.stores.Set(name, store)
+	c.mu.RLock()
+	hooks := c.hooks
+	c.mu.RUnlock()
+	if len(hooks) > 0 {
+		c.resolved[name] = NewHookCache(name, store, hooks...)
+	} else {
+		c.resolved[name] = store
 	}
 }
 
 // SetDefaultStore sets the default cache store name.
 func (c *CacheManager) SetDefaultStore(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.defaultStore = name
 }
 
+// DefaultStore returns the default cache store name.
+func (c *CacheManager) DefaultStore() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.defaultStore
+}
+
+// AddHook attaches hooks to every store resolved through the manager, including the default store
+// used by the cache API of the manager itself. Use [WithContext] on a resolved store to pass a context to the hooks.
+// Stores are wrapped once, use [Unwrap] or [As] to reach the stores added to the manager.
+func (c *CacheManager) AddHook(hooks ...Hook) {
+	c.stores.Lock()
+	defer c.stores.Unlock()
+	c.mu.Lock()
+	c.hooks = append(c.hooks, hooks...)
+	c.mu.Unlock()
+	for _, name := range c.stores.Keys() {
+		store, _ := c.stores.Get(name)
+		c.set(name, store)
+	}
+}
+
 // AddStore adds a cache store to the manager.
 func (c *CacheManager) AddStore(name string, store cache.Cache) {
 	c.stores.Lock()
 	defer c.stores.Unlock()
-	c.stores.Set(name, store)
+	c.set(name, store)
+}
+
+// ReplaceStore replaces the cache store and returns the previous one, or nil if there was none.
+// Calls which already resolved the previous store complete on it, so it should only be closed by the caller
+// once they are done.
+func (c *CacheManager) ReplaceStore(name string, store cache.Cache) cache.Cache {
+	c.stores.Lock()
+	defer c.stores.Unlock()
+	previous, _ := c.stores.Get(name)
+	c.set(name, store)
+	return previous
+}
+
+// RemoveStore removes the cache store from the manager and returns it, or nil if there was none.
+func (c *CacheManager) RemoveStore(name string) cache.Cache {
+	c.stores.Lock()
+	defer c.stores.Unlock()
+	store, ok := c.stores.Get(name)
+	if !ok {
+		return nil
+	}
+	c.stores.Remove(name)
+	delete(c.resolved, name)
+	return store
 }
 
 // HasStore checks if the cache store exists.
@@ -45,15 +112,14 @@ func (c *CacheManager) HasStore(name string) bool {
 	return false
 }
 
-// TryStore gets the cache store.
+// TryStore gets the cache store, wrapped with the hooks of the manager if any.
 // It will return an error if the cache store is not configured or error occurs.
 func (c *CacheManager) TryStore(name string) (cache.Cache, error) {
 	c.stores.RLock()
-	if store, ok := c.stores.Get(name); ok {
-		c.stores.RUnlock()
+	defer c.stores.RUnlock()
+	if store, ok := c.resolved[name]; ok {
 		return store, nil
 	}
-	c.stores.RUnlock()
 	return nil, NewStoreNotConfiguredException(name)
 }
 
@@ -66,3 +132,56 @@ func (c *CacheManager) GetStore(name string) cache.Cache {
 		return store
 	}
 }
+
+// store resolves the default cache store.
+func (c *CacheManager) store() (cache.Cache, error) {
+	return c.TryStore(c.DefaultStore())
+}
+
+func (c *CacheManager) Get(key string) (string, error) {
+	store, err := c.store()
+	if err != nil {
+		return "", err
+	}
+	return store.Get(key)
+}
+
+func (c *CacheManager) Set(key string, value string, expire time.Duration) error {
+	store, err := c.store()
+	if err != nil {
+		return err
+	}
+	return store.Set(key, value, expire)
+}
+
+func (c *CacheManager) Load(key string, loader func() (string, error), expire time.Duration) (string, error) {
+	store, err := c.store()
+	if err != nil {
+		return "", err
+	}
+	return store.Load(key, loader, expire)
+}
+
+func (c *CacheManager) Delete(key string) error {
+	store, err := c.store()
+	if err != nil {
+		return err
+	}
+	return store.Delete(key)
+}
+
+func (c *CacheManager) Has(key string) bool {
+	store, err := c.store()
+	if err != nil {
+		return false
+	}
+	return store.Has(key)
+}
+
+func (c *CacheManager) Clear() error {
+	store, err := c.store()
+	if err != nil {
+		return err
+	}
+	return store.Clear()
+}
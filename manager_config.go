@@ -0,0 +1,188 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+
+	"github.com/gopi-frame/contract/cache"
+	"github.com/gopi-frame/exception"
+)
+
+const (
+	// DriverKey is the reserved store config key holding the driver name.
+	DriverKey = "driver"
+	// LazyKey is the reserved store config key which defers opening the store until its first use.
+	LazyKey = "lazy"
+	// MiddlewaresKey is the reserved store config key holding the middlewares wrapping the store.
+	MiddlewaresKey = "middlewares"
+)
+
+// ManagerConfig is the cache manager config, such as
+//
+//	default: redis
+//	stores:
+//	  redis:
+//	    driver: redis
+//	    url: redis://localhost:6379/0
+//	  local:
+//	    driver: memory
+//	    lazy: true
+//	    expire: 10m
+//	    middlewares:
+//	      - name: slog
+//	        threshold: 50ms
+//	      - name: compress
+//	        algorithm: zstd
+//
+// Every store config holds the driver name under "driver", the other keys are passed to the driver.
+// Middlewares are given by registered name, or as a map holding the name under "name" and the middleware options,
+// the first one is the outermost.
+type ManagerConfig struct {
+	// Default is the default store name, it can be omitted if there is only one store.
+	Default string `json:"default" yaml:"default" toml:"default" mapstructure:"default"`
+	// Stores are the store configs keyed by store name.
+	Stores map[string]map[string]any `json:"stores" yaml:"stores" toml:"stores" mapstructure:"stores"`
+}
+
+// OpenCacheManager decodes config into a [ManagerConfig] and creates the cache manager from it.
+func OpenCacheManager(config map[string]any) (*CacheManager, error) {
+	var cfg ManagerConfig
+	if err := DecodeConfig(config, &cfg); err != nil {
+		return nil, err
+	}
+	return NewCacheManagerFromConfig(&cfg)
+}
+
+// NewCacheManagerFromConfig creates a cache manager and opens every configured store through the registered drivers.
+// All config errors are reported together, and the stores opened so far are discarded if any error occurs.
+func NewCacheManagerFromConfig(config *ManagerConfig) (*CacheManager, error) {
+	manager := NewCacheManager()
+	names := make([]string, 0, len(config.Stores))
+	for name := range config.Stores {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var errs []error
+	var opened []cache.Cache
+	for _, name := range names {
+		store, err := openStore(name, config.Stores[name])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("store \"%s\": %w", name, err))
+			continue
+		}
+		opened = append(opened, store)
+		manager.AddStore(name, store)
+	}
+	defaultStore := config.Default
+	if defaultStore == "" && len(names) == 1 {
+		defaultStore = names[0]
+	}
+	switch {
+	case defaultStore == "":
+		errs = append(errs, exception.NewEmptyArgumentException("default"))
+	case config.Stores[defaultStore] == nil:
+		errs = append(errs, NewStoreNotConfiguredException(defaultStore))
+	}
+	if len(errs) > 0 {
+		for _, store := range opened {
+			if closer, ok := store.(io.Closer); ok {
+				_ = closer.Close()
+			}
+		}
+		return nil, errors.Join(errs...)
+	}
+	manager.SetDefaultStore(defaultStore)
+	return manager, nil
+}
+
+func openStore(name string, config map[string]any) (cache.Cache, error) {
+	driverName, _ := config[DriverKey].(string)
+	if driverName == "" {
+		return nil, exception.NewEmptyArgumentException(DriverKey)
+	}
+	drivers.RLock()
+	_, ok := drivers.Get(driverName)
+	drivers.RUnlock()
+	if !ok {
+		return nil, exception.NewArgumentException(DriverKey, driverName, fmt.Sprintf("unknown driver \"%s\"", driverName))
+	}
+	var lazy bool
+	if v, ok := config[LazyKey]; ok {
+		if lazy, ok = v.(bool); !ok {
+			return nil, exception.NewArgumentException(LazyKey, v, "lazy must be a boolean")
+		}
+	}
+	mws, err := openMiddlewares(name, config[MiddlewaresKey])
+	if err != nil {
+		return nil, err
+	}
+	options := make(map[string]any, len(config))
+	for key, value := range config {
+		if key == DriverKey || key == LazyKey || key == MiddlewaresKey {
+			continue
+		}
+		options[key] = value
+	}
+	var store cache.Cache
+	if lazy {
+		store = NewDeferCache(driverName, options)
+	} else if store, err = Open(driverName, options); err != nil {
+		return nil, err
+	}
+	return Chain(store, mws...), nil
+}
+
+// openMiddlewares opens the middlewares of a store. The list and its items are matched by kind, so that
+// TOML arrays of tables ([]map[string]interface{}) and YAML v2 maps (map[interface{}]interface{}) are accepted.
+func openMiddlewares(store string, config any) ([]Middleware, error) {
+	if config == nil {
+		return nil, nil
+	}
+	var items []any
+	switch v := reflect.ValueOf(config); v.Kind() {
+	case reflect.String:
+		items = []any{v.String()}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			items = append(items, v.Index(i).Interface())
+		}
+	default:
+		return nil, exception.NewArgumentException(MiddlewaresKey, config, "middlewares must be a list")
+	}
+	mws := make([]Middleware, 0, len(items))
+	for _, item := range items {
+		var name string
+		options := make(map[string]any)
+		switch v := reflect.ValueOf(item); v.Kind() {
+		case reflect.String:
+			name = v.String()
+		case reflect.Map:
+			iter := v.MapRange()
+			for iter.Next() {
+				key, ok := iter.Key().Interface().(string)
+				if !ok {
+					return nil, exception.NewArgumentException(MiddlewaresKey, item, "middleware option names must be strings")
+				}
+				if key == "name" {
+					name, _ = iter.Value().Interface().(string)
+					continue
+				}
+				options[key] = iter.Value().Interface()
+			}
+		default:
+			return nil, exception.NewArgumentException(MiddlewaresKey, item, "middleware must be a name or a map")
+		}
+		if name == "" {
+			return nil, exception.NewEmptyArgumentException("middleware name")
+		}
+		mw, err := OpenMiddleware(name, store, options)
+		if err != nil {
+			return nil, fmt.Errorf("middleware \"%s\": %w", name, err)
+		}
+		mws = append(mws, mw)
+	}
+	return mws, nil
+}
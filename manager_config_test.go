@@ -0,0 +1,57 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenCacheManager(t *testing.T) {
+	t.Run("stores", func(t *testing.T) {
+		manager, err := OpenCacheManager(map[string]any{
+			"default": "map",
+			"stores": map[string]any{
+				"map":  map[string]any{"driver": "test-map"},
+				"lazy": map[string]any{"driver": "test-map", "lazy": true},
+			},
+		})
+		if err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		assert.True(t, manager.HasStore("map"))
+		assert.True(t, manager.HasStore("lazy"))
+		assert.IsType(t, new(mapStore), manager.GetStore("map"))
+		assert.IsType(t, new(DeferCache), manager.GetStore("lazy"))
+		assert.Equal(t, "map", manager.defaultStore)
+	})
+
+	t.Run("single store is default", func(t *testing.T) {
+		manager, err := NewCacheManagerFromConfig(&ManagerConfig{
+			Stores: map[string]map[string]any{
+				"map": {"driver": "test-map"},
+			},
+		})
+		if err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		assert.Equal(t, "map", manager.defaultStore)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := OpenCacheManager(map[string]any{
+			"default": "missing",
+			"stores": map[string]any{
+				"no-driver": map[string]any{},
+				"unknown":   map[string]any{"driver": "unknown"},
+				"failed":    map[string]any{"driver": "test-map", "fail": true},
+				"ok":        map[string]any{"driver": "test-map"},
+			},
+		})
+		assert.Error(t, err)
+		assert.ErrorContains(t, err, `store "no-driver"`)
+		assert.ErrorContains(t, err, `store "unknown"`)
+		assert.ErrorContains(t, err, `store "failed"`)
+		assert.ErrorContains(t, err, "missing")
+		assert.NotContains(t, err.Error(), `store "ok"`)
+	})
+}
@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheManager(t *testing.T) {
+	t.Run("without default store", func(t *testing.T) {
+		manager := NewCacheManager()
+		_, err := manager.Get("key")
+		assert.IsType(t, new(StoreNotConfiguredException), err)
+		assert.Error(t, manager.Set("key", "value", 0))
+		assert.False(t, manager.Has("key"))
+	})
+
+	t.Run("proxy to default store", func(t *testing.T) {
+		manager := NewCacheManager()
+		store1, store2 := newMapStore(), newMapStore()
+		manager.AddStore("store1", store1)
+		manager.AddStore("store2", store2)
+		manager.SetDefaultStore("store1")
+		assert.NoError(t, manager.Set("key", "value", 0))
+		assert.True(t, store1.Has("key"))
+		value, err := manager.Load("key", func() (string, error) {
+			return "value1", nil
+		}, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, "value", value)
+
+		manager.SetDefaultStore("store2")
+		assert.False(t, manager.Has("key"))
+		assert.NoError(t, manager.Set("key", "value2", 0))
+		value, err = store2.Get("key")
+		assert.NoError(t, err)
+		assert.Equal(t, "value2", value)
+		assert.NoError(t, manager.Delete("key"))
+		assert.False(t, store2.Has("key"))
+		assert.NoError(t, manager.Clear())
+		assert.True(t, store1.Has("key"))
+	})
+
+	t.Run("replace and remove store", func(t *testing.T) {
+		manager := NewCacheManager()
+		store1, store2 := newMapStore(), newMapStore()
+		assert.Nil(t, manager.ReplaceStore("default", store1))
+		manager.SetDefaultStore("default")
+		assert.NoError(t, manager.Set("key", "value", 0))
+		assert.Same(t, store1, manager.ReplaceStore("default", store2))
+		assert.False(t, manager.Has("key"))
+		assert.Same(t, store2, manager.RemoveStore("default"))
+		assert.Nil(t, manager.RemoveStore("default"))
+		assert.False(t, manager.HasStore("default"))
+		_, err := manager.Get("key")
+		assert.Error(t, err)
+	})
+
+	t.Run("concurrent switching", func(t *testing.T) {
+		manager := NewCacheManager()
+		manager.AddStore("store1", newMapStore())
+		manager.AddStore("store2", newMapStore())
+		manager.SetDefaultStore("store1")
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					assert.NoError(t, manager.Set("key", "value", 0))
+				}
+			}()
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					if (i+j)%2 == 0 {
+						manager.SetDefaultStore("store1")
+					} else {
+						manager.ReplaceStore("store2", newMapStore())
+						manager.SetDefaultStore("store2")
+					}
+				}
+			}(i)
+		}
+		wg.Wait()
+	})
+}
@@ -0,0 +1,233 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gopi-frame/contract/cache"
+)
+
+type memoizeOptions[R any] struct {
+	keyFunc      func(args ...any) (string, error)
+	ttlFunc      func(result R) time.Duration
+	skipZero     bool
+	cacheOptions []Option[R]
+}
+
+// MemoizeOption configures the memoized functions.
+type MemoizeOption[R any] func(opts *memoizeOptions[R])
+
+// WithKeyFunc sets the function building the key from the arguments, the prefix is still prepended.
+// By default every argument is encoded by [DefaultKeyEncoder] and the results are joined with colons.
+func WithKeyFunc[R any](keyFunc func(args ...any) (string, error)) MemoizeOption[R] {
+	return func(opts *memoizeOptions[R]) {
+		opts.keyFunc = keyFunc
+	}
+}
+
+// WithTTLFunc sets the expire time per result, results with a negative expire time are not cached.
+func WithTTLFunc[R any](ttlFunc func(result R) time.Duration) MemoizeOption[R] {
+	return func(opts *memoizeOptions[R]) {
+		opts.ttlFunc = ttlFunc
+	}
+}
+
+// WithSkipZero does not cache zero results, such as nil pointers and empty strings.
+func WithSkipZero[R any]() MemoizeOption[R] {
+	return func(opts *memoizeOptions[R]) {
+		opts.skipZero = true
+	}
+}
+
+// WithCacheOptions sets the options of the typed cache holding the results, such as its codec.
+func WithCacheOptions[R any](cacheOptions ...Option[R]) MemoizeOption[R] {
+	return func(opts *memoizeOptions[R]) {
+		opts.cacheOptions = append(opts.cacheOptions, cacheOptions...)
+	}
+}
+
+// memoizer caches the results of a function by its arguments.
+// Errors of the function are never cached, and errors of the store are returned.
+type memoizer[R any] struct {
+	cache    *Cache[R]
+	prefix   string
+	ttl      time.Duration
+	options  memoizeOptions[R]
+	encoders []func(arg any) (string, error)
+}
+
+// newMemoizer creates a memoizer, encoderErr is the error of creating the argument encoders,
+// which is ignored if a key function is set.
+func newMemoizer[R any](store cache.Cache, prefix string, ttl time.Duration, encoders []func(arg any) (string, error), encoderErr error, opts []MemoizeOption[R]) (*memoizer[R], error) {
+	m := &memoizer[R]{prefix: prefix, ttl: ttl, encoders: encoders}
+	for _, opt := range opts {
+		opt(&m.options)
+	}
+	if m.options.keyFunc == nil && encoderErr != nil {
+		return nil, encoderErr
+	}
+	c, err := New[R](store, m.options.cacheOptions...)
+	if err != nil {
+		return nil, err
+	}
+	m.cache = c
+	return m, nil
+}
+
+func argEncoder[A any]() (func(arg any) (string, error), error) {
+	encoder, err := DefaultKeyEncoder[A]()
+	if err != nil {
+		return nil, err
+	}
+	return func(arg any) (string, error) {
+		// nil interface arguments are passed as untyped nil
+		a, _ := arg.(A)
+		return encoder.EncodeKey(a)
+	}, nil
+}
+
+func (m *memoizer[R]) key(args ...any) (string, error) {
+	if m.options.keyFunc != nil {
+		key, err := m.options.keyFunc(args...)
+		if err != nil {
+			return "", err
+		}
+		return m.prefix + ":" + key, nil
+	}
+	parts := make([]string, 0, len(args)+1)
+	parts = append(parts, m.prefix)
+	for i, arg := range args {
+		part, err := m.encoders[i](arg)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, keyEscaper.Replace(part))
+	}
+	return strings.Join(parts, ":"), nil
+}
+
+func (m *memoizer[R]) call(fn func() (R, error), args ...any) (R, error) {
+	key, err := m.key(args...)
+	if err != nil {
+		return *new(R), err
+	}
+	result, err := m.cache.Get(key)
+	if err == nil || !errors.Is(err, ErrCacheNotFound) {
+		return result, err
+	}
+	result, err = fn()
+	if err != nil {
+		return result, err
+	}
+	if m.options.skipZero && reflect.ValueOf(&result).Elem().IsZero() {
+		return result, nil
+	}
+	ttl := m.ttl
+	if m.options.ttlFunc != nil {
+		ttl = m.options.ttlFunc(result)
+	}
+	if ttl >= 0 {
+		if err := m.cache.Set(key, result, ttl); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+func (m *memoizer[R]) forget(args ...any) error {
+	key, err := m.key(args...)
+	if err != nil {
+		return err
+	}
+	return m.cache.Delete(key)
+}
+
+// Memoized1 is a memoized function of one argument.
+type Memoized1[A any, R any] struct {
+	m  *memoizer[R]
+	fn func(ctx context.Context, a A) (R, error)
+}
+
+// Memoize1 caches the results of fn in store under keys such as "<prefix>:<a>" for ttl.
+func Memoize1[A any, R any](store cache.Cache, prefix string, ttl time.Duration, fn func(ctx context.Context, a A) (R, error), opts ...MemoizeOption[R]) (*Memoized1[A, R], error) {
+	encoderA, errA := argEncoder[A]()
+	m, err := newMemoizer[R](store, prefix, ttl, []func(any) (string, error){encoderA}, errA, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Memoized1[A, R]{m: m, fn: fn}, nil
+}
+
+// Call returns the cached result, or calls the function and caches its result.
+func (f *Memoized1[A, R]) Call(ctx context.Context, a A) (R, error) {
+	return f.m.call(func() (R, error) {
+		return f.fn(ctx, a)
+	}, a)
+}
+
+// Forget removes the cached result.
+func (f *Memoized1[A, R]) Forget(a A) error {
+	return f.m.forget(a)
+}
+
+// Memoized2 is a memoized function of two arguments.
+type Memoized2[A any, B any, R any] struct {
+	m  *memoizer[R]
+	fn func(ctx context.Context, a A, b B) (R, error)
+}
+
+// Memoize2 caches the results of fn in store under keys such as "<prefix>:<a>:<b>" for ttl.
+func Memoize2[A any, B any, R any](store cache.Cache, prefix string, ttl time.Duration, fn func(ctx context.Context, a A, b B) (R, error), opts ...MemoizeOption[R]) (*Memoized2[A, B, R], error) {
+	encoderA, errA := argEncoder[A]()
+	encoderB, errB := argEncoder[B]()
+	m, err := newMemoizer[R](store, prefix, ttl, []func(any) (string, error){encoderA, encoderB}, errors.Join(errA, errB), opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Memoized2[A, B, R]{m: m, fn: fn}, nil
+}
+
+// Call returns the cached result, or calls the function and caches its result.
+func (f *Memoized2[A, B, R]) Call(ctx context.Context, a A, b B) (R, error) {
+	return f.m.call(func() (R, error) {
+		return f.fn(ctx, a, b)
+	}, a, b)
+}
+
+// Forget removes the cached result.
+func (f *Memoized2[A, B, R]) Forget(a A, b B) error {
+	return f.m.forget(a, b)
+}
+
+// Memoized3 is a memoized function of three arguments.
+type Memoized3[A any, B any, C any, R any] struct {
+	m  *memoizer[R]
+	fn func(ctx context.Context, a A, b B, c C) (R, error)
+}
+
+// Memoize3 caches the results of fn in store under keys such as "<prefix>:<a>:<b>:<c>" for ttl.
+func Memoize3[A any, B any, C any, R any](store cache.Cache, prefix string, ttl time.Duration, fn func(ctx context.Context, a A, b B, c C) (R, error), opts ...MemoizeOption[R]) (*Memoized3[A, B, C, R], error) {
+	encoderA, errA := argEncoder[A]()
+	encoderB, errB := argEncoder[B]()
+	encoderC, errC := argEncoder[C]()
+	m, err := newMemoizer[R](store, prefix, ttl, []func(any) (string, error){encoderA, encoderB, encoderC}, errors.Join(errA, errB, errC), opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Memoized3[A, B, C, R]{m: m, fn: fn}, nil
+}
+
+// Call returns the cached result, or calls the function and caches its result.
+func (f *Memoized3[A, B, C, R]) Call(ctx context.Context, a A, b B, c C) (R, error) {
+	return f.m.call(func() (R, error) {
+		return f.fn(ctx, a, b, c)
+	}, a, b, c)
+}
+
+// Forget removes the cached result.
+func (f *Memoized3[A, B, C, R]) Forget(a A, b B, c C) error {
+	return f.m.forget(a, b, c)
+}
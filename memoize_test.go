@@ -0,0 +1,204 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type failingGetStore struct {
+	*mapStore
+}
+
+func (s *failingGetStore) Get(string) (string, error) {
+	return "", errors.New("connection refused")
+}
+
+func TestMemoize(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("one argument", func(t *testing.T) {
+		store := newMapStore()
+		calls := 0
+		user, err := Memoize1(store, "user", time.Minute, func(ctx context.Context, id int) (string, error) {
+			calls++
+			return fmt.Sprintf("user-%d", id), nil
+		})
+		if err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		for i := 0; i < 2; i++ {
+			value, err := user.Call(ctx, 42)
+			assert.NoError(t, err)
+			assert.Equal(t, "user-42", value)
+		}
+		assert.Equal(t, 1, calls)
+		assert.True(t, store.Has("user:42"))
+		assert.NoError(t, user.Forget(42))
+		assert.False(t, store.Has("user:42"))
+		_, _ = user.Call(ctx, 42)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("two and three arguments", func(t *testing.T) {
+		store := newMapStore()
+		greet, _ := Memoize2(store, "greet", time.Minute, func(ctx context.Context, name string, locale string) (string, error) {
+			return locale + ":" + name, nil
+		})
+		value, err := greet.Call(ctx, "gopi", "en:US")
+		assert.NoError(t, err)
+		assert.Equal(t, "en:US:gopi", value)
+		assert.True(t, store.Has(`greet:gopi:en\:US`))
+		assert.NoError(t, greet.Forget("gopi", "en:US"))
+		assert.False(t, store.Has(`greet:gopi:en\:US`))
+
+		sum, _ := Memoize3(store, "sum", time.Minute, func(ctx context.Context, a int, b int, c int) (int, error) {
+			return a + b + c, nil
+		})
+		total, err := sum.Call(ctx, 1, 2, 3)
+		assert.NoError(t, err)
+		assert.Equal(t, 6, total)
+		assert.True(t, store.Has("sum:1:2:3"))
+	})
+
+	t.Run("errors are not cached", func(t *testing.T) {
+		store := newMapStore()
+		calls := 0
+		failing, _ := Memoize1(store, "failing", time.Minute, func(ctx context.Context, id int) (string, error) {
+			calls++
+			return "", errors.New("failed")
+		})
+		_, err := failing.Call(ctx, 1)
+		assert.EqualError(t, err, "failed")
+		_, err = failing.Call(ctx, 1)
+		assert.Error(t, err)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("store errors are returned", func(t *testing.T) {
+		calls := 0
+		user, _ := Memoize1(&failingGetStore{mapStore: newMapStore()}, "user", time.Minute, func(ctx context.Context, id int) (string, error) {
+			calls++
+			return "gopi", nil
+		})
+		_, err := user.Call(ctx, 1)
+		assert.EqualError(t, err, "connection refused")
+		assert.Equal(t, 0, calls)
+	})
+
+	t.Run("results are set once", func(t *testing.T) {
+		store := &loadCountingStore{mapStore: newMapStore()}
+		user, _ := Memoize1(store, "user", time.Minute, func(ctx context.Context, id int) (string, error) {
+			return "gopi", nil
+		})
+		for i := 0; i < 2; i++ {
+			_, err := user.Call(ctx, 1)
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, 1, store.sets)
+		assert.True(t, store.Has("user:1"))
+	})
+
+	t.Run("nil interface argument", func(t *testing.T) {
+		store := newMapStore()
+		describe, err := Memoize1(store, "describe", time.Minute, func(ctx context.Context, v fmt.Stringer) (string, error) {
+			if v == nil {
+				return "nil", nil
+			}
+			return v.String(), nil
+		}, WithKeyFunc[string](func(args ...any) (string, error) {
+			return fmt.Sprint(args...), nil
+		}))
+		if err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		assert.NotPanics(t, func() {
+			value, err := describe.Call(ctx, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, "nil", value)
+		})
+
+		encoded, err := Memoize1(store, "encoded", time.Minute, func(ctx context.Context, v fmt.Stringer) (string, error) {
+			return "", nil
+		})
+		if err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		assert.NotPanics(t, func() {
+			_, err := encoded.Call(ctx, nil)
+			assert.Error(t, err)
+		})
+	})
+
+	t.Run("skip zero", func(t *testing.T) {
+		store := newMapStore()
+		find, _ := Memoize1(store, "find", time.Minute, func(ctx context.Context, id int) (*userV1, error) {
+			if id == 0 {
+				return nil, nil
+			}
+			return &userV1{Name: "gopi"}, nil
+		}, WithSkipZero[*userV1]())
+		value, err := find.Call(ctx, 0)
+		assert.NoError(t, err)
+		assert.Nil(t, value)
+		assert.False(t, store.Has("find:0"))
+		_, _ = find.Call(ctx, 1)
+		assert.True(t, store.Has("find:1"))
+	})
+
+	t.Run("skipped results are not errors of the store", func(t *testing.T) {
+		metrics := NewMetrics()
+		find, _ := Memoize1(metrics.Wrap("find", newMapStore()), "find", time.Minute, func(ctx context.Context, id int) (*userV1, error) {
+			return nil, nil
+		}, WithSkipZero[*userV1]())
+		_, err := find.Call(ctx, 0)
+		assert.NoError(t, err)
+		stats := metrics.Stats()[0]
+		assert.Equal(t, uint64(0), stats.LoadErrors)
+		assert.Equal(t, uint64(0), stats.Errors)
+		assert.Equal(t, uint64(0), stats.Sets)
+	})
+
+	t.Run("ttl per result", func(t *testing.T) {
+		store := newMapStore()
+		status, _ := Memoize1(store, "status", time.Minute, func(ctx context.Context, code int) (string, error) {
+			if code >= 500 {
+				return "error", nil
+			}
+			return "ok", nil
+		}, WithTTLFunc(func(result string) time.Duration {
+			if result == "error" {
+				return -1
+			}
+			return time.Hour
+		}))
+		_, _ = status.Call(ctx, 200)
+		_, _ = status.Call(ctx, 503)
+		assert.True(t, store.Has("status:200"))
+		assert.True(t, store.expire["status:200"].After(time.Now().Add(time.Minute)))
+		assert.False(t, store.Has("status:503"))
+	})
+
+	t.Run("key func", func(t *testing.T) {
+		store := newMapStore()
+		join, err := Memoize1(store, "join", time.Minute, func(ctx context.Context, tags []string) (string, error) {
+			return strings.Join(tags, ","), nil
+		}, WithKeyFunc[string](func(args ...any) (string, error) {
+			return strings.Join(args[0].([]string), "+"), nil
+		}))
+		if err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		_, _ = join.Call(ctx, []string{"a", "b"})
+		assert.True(t, store.Has("join:a+b"))
+		_, err = Memoize1(store, "join", time.Minute, func(ctx context.Context, tags []string) (string, error) {
+			return "", nil
+		})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,430 @@
+package cache
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gopi-frame/contract/cache"
+)
+
+// Cache operation names used as the "op" label of the latency histograms.
+const (
+	OpGet    = "get"
+	OpSet    = "set"
+	OpLoad   = "load"
+	OpDelete = "delete"
+	OpHas    = "has"
+	OpClear  = "clear"
+)
+
+var metricOps = []string{OpGet, OpSet, OpLoad, OpDelete, OpHas, OpClear}
+
+// DefaultLatencyBuckets are the default upper bounds of the latency histograms.
+var DefaultLatencyBuckets = []time.Duration{
+	100 * time.Microsecond,
+	500 * time.Microsecond,
+	time.Millisecond,
+	2500 * time.Microsecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+}
+
+// MetricsOption configures [Metrics].
+type MetricsOption func(m *Metrics)
+
+// WithNamespace sets the prefix of the exported metric names, default is "cache".
+func WithNamespace(namespace string) MetricsOption {
+	return func(m *Metrics) {
+		if namespace == "" {
+			return
+		}
+		m.namespace = namespace
+	}
+}
+
+// WithLatencyBuckets sets the upper bounds of the latency histograms.
+func WithLatencyBuckets(buckets ...time.Duration) MetricsOption {
+	return func(m *Metrics) {
+		if len(buckets) == 0 {
+			return
+		}
+		buckets = append([]time.Duration(nil), buckets...)
+		sort.Slice(buckets, func(i, j int) bool { return buckets[i] < buckets[j] })
+		m.buckets = buckets
+	}
+}
+
+// EvictionNotifier is implemented by stores which remove values on their own, because the values expired
+// or the store is full.
+type EvictionNotifier interface {
+	// OnEvict registers fn to be called with the key of every value removed by the store.
+	OnEvict(fn func(key string))
+}
+
+// Metrics collects the operation counters and latencies of the stores wrapped by it.
+// It implements [http.Handler] and writes the metrics in the Prometheus text format.
+type Metrics struct {
+	namespace string
+	buckets   []time.Duration
+
+	mu     sync.RWMutex
+	stores map[string]*storeMetrics
+}
+
+// NewMetrics creates a new metrics collector.
+func NewMetrics(opts ...MetricsOption) *Metrics {
+	m := &Metrics{
+		namespace: "cache",
+		buckets:   DefaultLatencyBuckets,
+		stores:    make(map[string]*storeMetrics),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+func (m *Metrics) store(name string) *storeMetrics {
+	m.mu.RLock()
+	s, ok := m.stores[name]
+	m.mu.RUnlock()
+	if ok {
+		return s
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if s, ok := m.stores[name]; ok {
+		return s
+	}
+	s = &storeMetrics{latency: make(map[string]*histogram, len(metricOps))}
+	for _, op := range metricOps {
+		s.latency[op] = newHistogram(len(m.buckets))
+	}
+	m.stores[name] = s
+	return s
+}
+
+// Wrap returns store with its operations recorded under the store name.
+// Stores wrapped with the same name share their metrics.
+// Evictions are counted if store or a store wrapped by it implements [EvictionNotifier].
+func (m *Metrics) Wrap(name string, store cache.Cache) *MetricsCache {
+	stats := m.store(name)
+	if notifier, ok := As[EvictionNotifier](store); ok {
+		notifier.OnEvict(func(string) {
+			stats.evictions.Add(1)
+		})
+	}
+	return &MetricsCache{
+		Forwarder: Forwarder{Next: store},
+		metrics:   m,
+		stats:     stats,
+	}
+}
+
+// Middleware returns a middleware which wraps stores with [Metrics.Wrap].
+func (m *Metrics) Middleware(name string) Middleware {
+	return func(next cache.Cache) cache.Cache {
+		return m.Wrap(name, next)
+	}
+}
+
+// Stats returns a snapshot of the metrics of every store, sorted by store name.
+func (m *Metrics) Stats() []StoreStats {
+	m.mu.RLock()
+	names := make([]string, 0, len(m.stores))
+	for name := range m.stores {
+		names = append(names, name)
+	}
+	m.mu.RUnlock()
+	sort.Strings(names)
+	stats := make([]StoreStats, len(names))
+	for i, name := range names {
+		stats[i] = m.store(name).snapshot(name, m.buckets)
+	}
+	return stats
+}
+
+// ServeHTTP writes the metrics in the Prometheus text format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = m.WritePrometheus(w)
+}
+
+// WritePrometheus writes the metrics in the Prometheus text format to w.
+func (m *Metrics) WritePrometheus(w io.Writer) error {
+	stats := m.Stats()
+	buf := bufio.NewWriter(w)
+	counters := []struct {
+		name  string
+		help  string
+		value func(s StoreStats) uint64
+	}{
+		{"hits_total", "Number of cache hits.", func(s StoreStats) uint64 { return s.Hits }},
+		{"misses_total", "Number of cache misses.", func(s StoreStats) uint64 { return s.Misses }},
+		{"loads_total", "Number of values loaded by loaders.", func(s StoreStats) uint64 { return s.Loads }},
+		{"load_errors_total", "Number of failed loaders.", func(s StoreStats) uint64 { return s.LoadErrors }},
+		{"sets_total", "Number of values set.", func(s StoreStats) uint64 { return s.Sets }},
+		{"deletes_total", "Number of values deleted.", func(s StoreStats) uint64 { return s.Deletes }},
+		{"clears_total", "Number of times the cache was cleared.", func(s StoreStats) uint64 { return s.Clears }},
+		{"evictions_total", "Number of values removed by the store.", func(s StoreStats) uint64 { return s.Evictions }},
+		{"errors_total", "Number of failed operations.", func(s StoreStats) uint64 { return s.Errors }},
+	}
+	for _, counter := range counters {
+		name := m.namespace + "_" + counter.name
+		_, _ = fmt.Fprintf(buf, "# HELP %s %s\n# TYPE %s counter\n", name, counter.help, name)
+		for _, s := range stats {
+			_, _ = fmt.Fprintf(buf, "%s{store=\"%s\"} %d\n", name, escapeLabel(s.Store), counter.value(s))
+		}
+	}
+	name := m.namespace + "_operation_duration_seconds"
+	_, _ = fmt.Fprintf(buf, "# HELP %s Latency of cache operations.\n# TYPE %s histogram\n", name, name)
+	for _, s := range stats {
+		for _, op := range metricOps {
+			latency := s.Latency[op]
+			labels := fmt.Sprintf("store=\"%s\",op=\"%s\"", escapeLabel(s.Store), op)
+			for _, bucket := range latency.Buckets {
+				_, _ = fmt.Fprintf(buf, "%s_bucket{%s,le=\"%s\"} %d\n", name, labels, formatSeconds(bucket.UpperBound), bucket.Count)
+			}
+			_, _ = fmt.Fprintf(buf, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, labels, latency.Count)
+			_, _ = fmt.Fprintf(buf, "%s_sum{%s} %s\n", name, labels, formatSeconds(latency.Sum))
+			_, _ = fmt.Fprintf(buf, "%s_count{%s} %d\n", name, labels, latency.Count)
+		}
+	}
+	return buf.Flush()
+}
+
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'g', -1, 64)
+}
+
+// StoreStats is a snapshot of the metrics of a store.
+type StoreStats struct {
+	Store      string `json:"store"`
+	Hits       uint64 `json:"hits"`
+	Misses     uint64 `json:"misses"`
+	Loads      uint64 `json:"loads"`
+	LoadErrors uint64 `json:"load_errors"`
+	Sets       uint64 `json:"sets"`
+	Deletes    uint64 `json:"deletes"`
+	Clears     uint64 `json:"clears"`
+	Evictions  uint64 `json:"evictions"`
+	Errors     uint64 `json:"errors"`
+	// Latency is the latency histogram keyed by operation name.
+	Latency map[string]LatencyStats `json:"latency"`
+}
+
+// HitRatio returns the ratio of hits to lookups, or 0 if there was no lookup.
+func (s StoreStats) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// LatencyStats is a snapshot of a latency histogram.
+type LatencyStats struct {
+	Count uint64        `json:"count"`
+	Sum   time.Duration `json:"sum"`
+	// Buckets are cumulative, every bucket counts the operations not slower than its upper bound.
+	Buckets []LatencyBucket `json:"buckets"`
+}
+
+// LatencyBucket is a bucket of a latency histogram.
+type LatencyBucket struct {
+	UpperBound time.Duration `json:"upper_bound"`
+	Count      uint64        `json:"count"`
+}
+
+type storeMetrics struct {
+	hits       atomic.Uint64
+	misses     atomic.Uint64
+	loads      atomic.Uint64
+	loadErrors atomic.Uint64
+	sets       atomic.Uint64
+	deletes    atomic.Uint64
+	clears     atomic.Uint64
+	evictions  atomic.Uint64
+	errors     atomic.Uint64
+	latency    map[string]*histogram
+}
+
+func (s *storeMetrics) snapshot(name string, buckets []time.Duration) StoreStats {
+	stats := StoreStats{
+		Store:      name,
+		Hits:       s.hits.Load(),
+		Misses:     s.misses.Load(),
+		Loads:      s.loads.Load(),
+		LoadErrors: s.loadErrors.Load(),
+		Sets:       s.sets.Load(),
+		Deletes:    s.deletes.Load(),
+		Clears:     s.clears.Load(),
+		Evictions:  s.evictions.Load(),
+		Errors:     s.errors.Load(),
+		Latency:    make(map[string]LatencyStats, len(s.latency)),
+	}
+	for op, h := range s.latency {
+		stats.Latency[op] = h.snapshot(buckets)
+	}
+	return stats
+}
+
+type histogram struct {
+	counts []atomic.Uint64
+	count  atomic.Uint64
+	sum    atomic.Int64
+}
+
+func newHistogram(buckets int) *histogram {
+	return &histogram{counts: make([]atomic.Uint64, buckets)}
+}
+
+func (h *histogram) observe(buckets []time.Duration, d time.Duration) {
+	i := sort.Search(len(buckets), func(i int) bool { return d <= buckets[i] })
+	if i < len(buckets) {
+		h.counts[i].Add(1)
+	}
+	h.sum.Add(int64(d))
+	h.count.Add(1)
+}
+
+func (h *histogram) snapshot(buckets []time.Duration) LatencyStats {
+	stats := LatencyStats{
+		Count:   h.count.Load(),
+		Sum:     time.Duration(h.sum.Load()),
+		Buckets: make([]LatencyBucket, len(buckets)),
+	}
+	var cumulative uint64
+	for i, bound := range buckets {
+		cumulative += h.counts[i].Load()
+		stats.Buckets[i] = LatencyBucket{UpperBound: bound, Count: cumulative}
+	}
+	return stats
+}
+
+// MetricsCache records the operations of a store, it is created by [Metrics.Wrap].
+//
+// Get and Load count hits and misses, Load also counts the loader calls and failures.
+// Clear is counted as a clear. Values removed by the store are counted as evictions if the store reports them
+// through [EvictionNotifier].
+// The latency of Load includes the time taken by the loader.
+type MetricsCache struct {
+	Forwarder
+	metrics *Metrics
+	stats   *storeMetrics
+}
+
+func (c *MetricsCache) observe(op string, start time.Time, err error) {
+	c.stats.latency[op].observe(c.metrics.buckets, time.Since(start))
+	if err != nil && !errors.Is(err, ErrCacheNotFound) {
+		c.stats.errors.Add(1)
+	}
+}
+
+func (c *MetricsCache) Get(key string) (string, error) {
+	start := time.Now()
+	value, err := c.Next.Get(key)
+	c.observe(OpGet, start, err)
+	switch {
+	case err == nil:
+		c.stats.hits.Add(1)
+	case errors.Is(err, ErrCacheNotFound):
+		c.stats.misses.Add(1)
+	}
+	return value, err
+}
+
+func (c *MetricsCache) Set(key string, value string, expire time.Duration) error {
+	start := time.Now()
+	err := c.Next.Set(key, value, expire)
+	c.observe(OpSet, start, err)
+	if err == nil {
+		c.stats.sets.Add(1)
+	}
+	return err
+}
+
+func (c *MetricsCache) Load(key string, loader func() (string, error), expire time.Duration) (string, error) {
+	start := time.Now()
+	var loaded bool
+	var loaderErr error
+	value, err := c.Next.Load(key, func() (string, error) {
+		loaded = true
+		value, err := loader()
+		loaderErr = err
+		return value, err
+	}, expire)
+	switch {
+	case loaded:
+		c.stats.misses.Add(1)
+		c.stats.loads.Add(1)
+		if loaderErr != nil {
+			c.stats.loadErrors.Add(1)
+		}
+	case err == nil:
+		c.stats.hits.Add(1)
+	}
+	if loaderErr != nil {
+		// failed loaders are counted as load errors only
+		c.observe(OpLoad, start, nil)
+	} else {
+		c.observe(OpLoad, start, err)
+	}
+	return value, err
+}
+
+func (c *MetricsCache) Delete(key string) error {
+	start := time.Now()
+	err := c.Next.Delete(key)
+	c.observe(OpDelete, start, err)
+	if err == nil {
+		c.stats.deletes.Add(1)
+	}
+	return err
+}
+
+func (c *MetricsCache) Has(key string) bool {
+	start := time.Now()
+	ok := c.Next.Has(key)
+	c.observe(OpHas, start, nil)
+	return ok
+}
+
+func (c *MetricsCache) Clear() error {
+	start := time.Now()
+	err := c.Next.Clear()
+	c.observe(OpClear, start, err)
+	if err == nil {
+		c.stats.clears.Add(1)
+	}
+	return err
+}
+
+// WithMetrics records the operations of the cache under the store name.
+func WithMetrics[T any](metrics *Metrics, name string) OptionFunc[T] {
+	return func(c *Cache[T]) error {
+		if metrics == nil {
+			return nil
+		}
+		c.Cache = metrics.Wrap(name, c.Cache)
+		return nil
+	}
+}
@@ -0,0 +1,123 @@
+package cache
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	t.Run("counters", func(t *testing.T) {
+		metrics := NewMetrics()
+		store := metrics.Wrap("local", newMapStore())
+		assert.NoError(t, store.Set("key", "value", 0))
+		_, _ = store.Get("key")
+		_, _ = store.Get("missing")
+		_, _ = store.Load("key", func() (string, error) { return "", nil }, 0)
+		_, _ = store.Load("loaded", func() (string, error) { return "value", nil }, 0)
+		_, err := store.Load("failed", func() (string, error) { return "", errors.New("failed") }, 0)
+		assert.Error(t, err)
+		assert.NoError(t, store.Delete("key"))
+		assert.NoError(t, store.Clear())
+
+		stats := metrics.Stats()
+		if !assert.Len(t, stats, 1) {
+			return
+		}
+		s := stats[0]
+		assert.Equal(t, "local", s.Store)
+		assert.Equal(t, uint64(2), s.Hits)
+		assert.Equal(t, uint64(3), s.Misses)
+		assert.Equal(t, uint64(2), s.Loads)
+		assert.Equal(t, uint64(1), s.LoadErrors)
+		assert.Equal(t, uint64(1), s.Sets)
+		assert.Equal(t, uint64(1), s.Deletes)
+		assert.Equal(t, uint64(1), s.Clears)
+		assert.Equal(t, uint64(0), s.Errors)
+		assert.InDelta(t, 0.4, s.HitRatio(), 0.0001)
+		assert.Equal(t, uint64(2), s.Latency[OpGet].Count)
+		assert.Equal(t, uint64(3), s.Latency[OpLoad].Count)
+		last := s.Latency[OpGet].Buckets[len(s.Latency[OpGet].Buckets)-1]
+		assert.Equal(t, uint64(2), last.Count)
+	})
+
+	t.Run("shared by store name", func(t *testing.T) {
+		metrics := NewMetrics()
+		_ = metrics.Wrap("local", newMapStore()).Set("key", "value", 0)
+		_ = metrics.Wrap("local", newMapStore()).Set("key", "value", 0)
+		_ = metrics.Wrap("remote", newMapStore()).Set("key", "value", 0)
+		stats := metrics.Stats()
+		if assert.Len(t, stats, 2) {
+			assert.Equal(t, uint64(2), stats[0].Sets)
+			assert.Equal(t, "remote", stats[1].Store)
+		}
+	})
+
+	t.Run("evictions", func(t *testing.T) {
+		metrics := NewMetrics()
+		store := &evictingStore{mapStore: newMapStore()}
+		_ = metrics.Wrap("evicting", Chain(store, metrics.Middleware("inner")))
+		_ = metrics.Wrap("plain", newMapStore())
+		store.evict("key")
+		store.evict("other")
+		stats := metrics.Stats()
+		if assert.Len(t, stats, 3) {
+			assert.Equal(t, uint64(2), stats[0].Evictions)
+			assert.Equal(t, uint64(2), stats[1].Evictions)
+			assert.Equal(t, uint64(0), stats[2].Evictions)
+		}
+	})
+
+	t.Run("typed cache", func(t *testing.T) {
+		metrics := NewMetrics()
+		c, err := New[int](newMapStore(), WithMetrics[int](metrics, "typed"))
+		assert.NoError(t, err)
+		assert.NoError(t, c.Set("key", 1, 0))
+		value, err := c.Get("key")
+		assert.NoError(t, err)
+		assert.Equal(t, 1, value)
+		assert.Equal(t, uint64(1), metrics.Stats()[0].Hits)
+	})
+
+	t.Run("prometheus", func(t *testing.T) {
+		metrics := NewMetrics(WithNamespace("app_cache"), WithLatencyBuckets(time.Second, time.Millisecond))
+		store := metrics.Wrap(`a"b`, newMapStore())
+		_, _ = store.Get("key")
+		recorder := httptest.NewRecorder()
+		metrics.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.True(t, strings.HasPrefix(recorder.Header().Get("Content-Type"), "text/plain"))
+		body := recorder.Body.String()
+		assert.Contains(t, body, "# TYPE app_cache_misses_total counter\n")
+		assert.Contains(t, body, "app_cache_misses_total{store=\"a\\\"b\"} 1\n")
+		assert.Contains(t, body, "# TYPE app_cache_clears_total counter\n")
+		assert.Contains(t, body, "# TYPE app_cache_evictions_total counter\n")
+		assert.Contains(t, body, "# TYPE app_cache_operation_duration_seconds histogram\n")
+		assert.Contains(t, body, "app_cache_operation_duration_seconds_bucket{store=\"a\\\"b\",op=\"get\",le=\"0.001\"} 1\n")
+		assert.Contains(t, body, "app_cache_operation_duration_seconds_bucket{store=\"a\\\"b\",op=\"get\",le=\"1\"} 1\n")
+		assert.Contains(t, body, "app_cache_operation_duration_seconds_bucket{store=\"a\\\"b\",op=\"get\",le=\"+Inf\"} 1\n")
+		assert.Contains(t, body, "app_cache_operation_duration_seconds_count{store=\"a\\\"b\",op=\"set\"} 0\n")
+	})
+}
+
+// evictingStore reports the keys passed to evict as evictions.
+type evictingStore struct {
+	*mapStore
+	onEvict []func(key string)
+}
+
+func (s *evictingStore) OnEvict(fn func(key string)) {
+	s.onEvict = append(s.onEvict, fn)
+}
+
+func (s *evictingStore) evict(key string) {
+	_ = s.Delete(key)
+	for _, fn := range s.onEvict {
+		fn(key)
+	}
+}
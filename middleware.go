@@ -0,0 +1,138 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/gopi-frame/collection/kv"
+	"github.com/gopi-frame/contract/cache"
+	"github.com/gopi-frame/exception"
+)
+
+// Middleware wraps a store to change or observe its operations.
+type Middleware func(next cache.Cache) cache.Cache
+
+// MiddlewareFactory creates a configured middleware for the named store.
+type MiddlewareFactory func(store string, options map[string]any) (Middleware, error)
+
+var middlewares = kv.NewMap[string, MiddlewareFactory]()
+
+// RegisterMiddleware registers a middleware factory which can be attached to stores by name in the manager config.
+func RegisterMiddleware(name string, factory MiddlewareFactory) {
+	middlewares.Lock()
+	defer middlewares.Unlock()
+	if factory == nil {
+		panic(exception.NewEmptyArgumentException("factory"))
+	}
+	if middlewares.ContainsKey(name) {
+		panic(exception.NewArgumentException("name", name, fmt.Sprintf("duplicate middleware \"%s\"", name)))
+	}
+	middlewares.Set(name, factory)
+}
+
+// Middlewares lists registered middlewares.
+func Middlewares() []string {
+	middlewares.RLock()
+	defer middlewares.RUnlock()
+	return middlewares.Keys()
+}
+
+// OpenMiddleware creates the registered middleware for the named store.
+func OpenMiddleware(name string, store string, options map[string]any) (Middleware, error) {
+	middlewares.RLock()
+	factory, ok := middlewares.Get(name)
+	middlewares.RUnlock()
+	if !ok {
+		return nil, exception.NewArgumentException("name", name, fmt.Sprintf("unknown middleware \"%s\"", name))
+	}
+	return factory(store, options)
+}
+
+// Chain wraps store with mws, the first middleware is the outermost one and sees every call first.
+func Chain(store cache.Cache, mws ...Middleware) cache.Cache {
+	for i := len(mws) - 1; i >= 0; i-- {
+		store = mws[i](store)
+	}
+	return store
+}
+
+// Unwrapper is implemented by stores which wrap another store, such as the stores embedding [Forwarder].
+type Unwrapper interface {
+	Unwrap() cache.Cache
+}
+
+// Unwrap returns the innermost store of a chain of wrappers.
+func Unwrap(store cache.Cache) cache.Cache {
+	for {
+		wrapper, ok := store.(Unwrapper)
+		if !ok {
+			return store
+		}
+		store = wrapper.Unwrap()
+	}
+}
+
+// As returns the first store of a chain of wrappers which implements T, such as [HashStore] or [HealthChecker].
+func As[T any](store cache.Cache) (T, bool) {
+	for store != nil {
+		if target, ok := store.(T); ok {
+			return target, true
+		}
+		wrapper, ok := store.(Unwrapper)
+		if !ok {
+			break
+		}
+		store = wrapper.Unwrap()
+	}
+	return *new(T), false
+}
+
+// Forwarder forwards every operation to the next store.
+// Wrappers embed it and override the operations they change.
+type Forwarder struct {
+	Next cache.Cache
+}
+
+// Unwrap returns the next store.
+func (f Forwarder) Unwrap() cache.Cache {
+	return f.Next
+}
+
+func (f Forwarder) Get(key string) (string, error) {
+	return f.Next.Get(key)
+}
+
+func (f Forwarder) Set(key string, value string, expire time.Duration) error {
+	return f.Next.Set(key, value, expire)
+}
+
+func (f Forwarder) Load(key string, loader func() (string, error), expire time.Duration) (string, error) {
+	return f.Next.Load(key, loader, expire)
+}
+
+func (f Forwarder) Delete(key string) error {
+	return f.Next.Delete(key)
+}
+
+func (f Forwarder) Has(key string) bool {
+	return f.Next.Has(key)
+}
+
+func (f Forwarder) Clear() error {
+	return f.Next.Clear()
+}
+
+// CheckHealth checks the health of the next store.
+func (f Forwarder) CheckHealth(ctx context.Context) error {
+	return CheckHealth(ctx, f.Next)
+}
+
+// Close closes the next store if it can be closed.
+func (f Forwarder) Close() error {
+	if closer, ok := f.Next.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
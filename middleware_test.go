@@ -0,0 +1,164 @@
+package cache
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gopi-frame/contract/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+// upperCache stores values in upper case, it only overrides Set.
+type upperCache struct {
+	Forwarder
+}
+
+func (c upperCache) Set(key string, value string, expire time.Duration) error {
+	return c.Next.Set(key, strings.ToUpper(value), expire)
+}
+
+func suffixMiddleware(suffix string) Middleware {
+	return func(next cache.Cache) cache.Cache {
+		return suffixCache{Forwarder{Next: next}, suffix}
+	}
+}
+
+type suffixCache struct {
+	Forwarder
+	suffix string
+}
+
+func (c suffixCache) Set(key string, value string, expire time.Duration) error {
+	return c.Next.Set(key, value+c.suffix, expire)
+}
+
+func init() {
+	RegisterMiddleware("test-suffix", func(_ string, options map[string]any) (Middleware, error) {
+		suffix, _ := options["suffix"].(string)
+		return suffixMiddleware(suffix), nil
+	})
+}
+
+func TestChain(t *testing.T) {
+	store := newMapStore()
+	c := Chain(store, suffixMiddleware("-a"), suffixMiddleware("-b"), func(next cache.Cache) cache.Cache {
+		return upperCache{Forwarder{Next: next}}
+	})
+	assert.NoError(t, c.Set("key", "value", 0))
+	value, err := c.Get("key")
+	assert.NoError(t, err)
+	assert.Equal(t, "VALUE-A-B", value)
+	assert.True(t, c.Has("key"))
+	assert.NoError(t, c.Delete("key"))
+	assert.False(t, store.Has("key"))
+	assert.NoError(t, c.(suffixCache).Close())
+	assert.True(t, store.closed)
+	assert.Same(t, store, Chain(store))
+}
+
+func TestOpenCacheManager_Middlewares(t *testing.T) {
+	t.Run("attach", func(t *testing.T) {
+		manager, err := OpenCacheManager(map[string]any{
+			"stores": map[string]any{
+				"map": map[string]any{
+					"driver": "test-map",
+					"middlewares": []any{
+						map[string]any{"name": "test-suffix", "suffix": "-a"},
+						"test-suffix",
+						map[string]any{"name": "slog", "threshold": "1s"},
+					},
+				},
+			},
+		})
+		if err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		assert.NoError(t, manager.Set("key", "value", 0))
+		value, err := manager.Get("key")
+		assert.NoError(t, err)
+		assert.Equal(t, "value-a", value)
+	})
+
+	t.Run("toml array of tables", func(t *testing.T) {
+		// [stores.map]
+		// driver = "test-map"
+		// [[stores.map.middlewares]]
+		// name = "test-suffix"
+		// suffix = "-a"
+		manager, err := OpenCacheManager(map[string]any{
+			"stores": map[string]any{
+				"map": map[string]any{
+					"driver": "test-map",
+					"middlewares": []map[string]interface{}{
+						{"name": "test-suffix", "suffix": "-a"},
+						{"name": "test-suffix", "suffix": "-b"},
+					},
+				},
+			},
+		})
+		if err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		assert.NoError(t, manager.Set("key", "value", 0))
+		value, err := manager.Get("key")
+		assert.NoError(t, err)
+		assert.Equal(t, "value-a-b", value)
+	})
+
+	t.Run("yaml v2 maps", func(t *testing.T) {
+		manager, err := OpenCacheManager(map[string]any{
+			"stores": map[string]any{
+				"map": map[string]any{
+					"driver": "test-map",
+					"middlewares": []interface{}{
+						map[interface{}]interface{}{"name": "test-suffix", "suffix": "-a"},
+					},
+				},
+			},
+		})
+		if err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		assert.NoError(t, manager.Set("key", "value", 0))
+		value, err := manager.Get("key")
+		assert.NoError(t, err)
+		assert.Equal(t, "value-a", value)
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		_, err := OpenCacheManager(map[string]any{
+			"stores": map[string]any{
+				"map": map[string]any{
+					"driver":      "test-map",
+					"middlewares": []string{"unknown"},
+				},
+			},
+		})
+		assert.ErrorContains(t, err, `middleware "unknown"`)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := OpenCacheManager(map[string]any{
+			"stores": map[string]any{
+				"map": map[string]any{
+					"driver":      "test-map",
+					"middlewares": []any{map[string]any{"suffix": "-a"}},
+				},
+			},
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("not a list", func(t *testing.T) {
+		_, err := OpenCacheManager(map[string]any{
+			"stores": map[string]any{
+				"map": map[string]any{
+					"driver":      "test-map",
+					"middlewares": 1,
+				},
+			},
+		})
+		assert.ErrorContains(t, err, "middlewares must be a list")
+	})
+}
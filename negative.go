@@ -0,0 +1,189 @@
+package cache
+
+import (
+	"encoding/binary"
+	"errors"
+	"time"
+
+	"github.com/gopi-frame/contract/cache"
+	"github.com/gopi-frame/exception"
+)
+
+// negativePrefix starts the envelopes written by [NegativeCache]. A tombstone is the prefix followed by
+// the tombstone marker and the uvarint encoded index of the error in the config, values starting with the prefix
+// are escaped with the prefix and the escape marker, so tombstones never collide with real values.
+var negativePrefix = envelopePrefix(envelopeNegative)
+
+// Markers following [negativePrefix].
+const (
+	tombstoneMarker byte = 't'
+	escapeMarker    byte = 'v'
+)
+
+// errStaleTombstone is returned by the decoding of a tombstone of an error which is no longer configured.
+var errStaleTombstone = errors.New("stale tombstone")
+
+// NegativeConfig is the negative caching config.
+type NegativeConfig struct {
+	// Errors are the errors of loaders which are cached, such as gorm.ErrRecordNotFound.
+	// Errors returned by loaders are matched with [errors.Is].
+	// Tombstones refer to errors by their index, so errors should only be appended while tombstones are cached.
+	Errors []error
+	// TTL is the expire time of the cached errors, default is 30 seconds.
+	TTL time.Duration
+}
+
+// ApplyDefaults sets the default values of unset fields.
+func (c *NegativeConfig) ApplyDefaults() {
+	if c.TTL <= 0 {
+		c.TTL = 30 * time.Second
+	}
+}
+
+// NegativeCache caches the configured errors of loaders as tombstones, and returns the same error
+// while the tombstone is cached instead of calling the loader again.
+type NegativeCache struct {
+	Forwarder
+	errors []error
+	ttl    time.Duration
+}
+
+// NewNegativeCache creates a cache which caches the configured loader errors of store.
+func NewNegativeCache(store cache.Cache, config *NegativeConfig) (*NegativeCache, error) {
+	if len(config.Errors) == 0 {
+		return nil, exception.NewEmptyArgumentException("errors")
+	}
+	config.ApplyDefaults()
+	return &NegativeCache{
+		Forwarder: Forwarder{Next: store},
+		errors:    config.Errors,
+		ttl:       config.TTL,
+	}, nil
+}
+
+// NegativeMiddleware returns a middleware which wraps stores with [NewNegativeCache].
+func NegativeMiddleware(config *NegativeConfig) (Middleware, error) {
+	if len(config.Errors) == 0 {
+		return nil, exception.NewEmptyArgumentException("errors")
+	}
+	return func(next cache.Cache) cache.Cache {
+		c, _ := NewNegativeCache(next, config)
+		return c
+	}, nil
+}
+
+// match returns the index of the configured error err matches.
+func (c *NegativeCache) match(err error) (int, bool) {
+	for i, target := range c.errors {
+		if errors.Is(err, target) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// tombstone returns the tombstone of the configured error at index.
+func (c *NegativeCache) tombstone(index int) string {
+	return string(binary.AppendUvarint([]byte(negativePrefix+string(tombstoneMarker)), uint64(index)))
+}
+
+// decode returns the value, or the cached error if value is a tombstone.
+// Tombstones of errors which are no longer configured return errStaleTombstone.
+func (c *NegativeCache) decode(value string) (string, error) {
+	payload, ok := unwrapEnvelope(envelopeNegative, value)
+	if !ok || payload == "" {
+		return value, nil
+	}
+	switch payload[0] {
+	case tombstoneMarker:
+		index, n := binary.Uvarint([]byte(payload[1:]))
+		if n <= 0 || n != len(payload)-1 || index >= uint64(len(c.errors)) {
+			return "", errStaleTombstone
+		}
+		return "", c.errors[index]
+	case escapeMarker:
+		return payload[1:], nil
+	default:
+		return value, nil
+	}
+}
+
+func (c *NegativeCache) encode(value string) string {
+	if _, ok := unwrapEnvelope(envelopeNegative, value); ok {
+		return negativePrefix + string(escapeMarker) + value
+	}
+	return value
+}
+
+func (c *NegativeCache) Get(key string) (string, error) {
+	value, err := c.Next.Get(key)
+	if err != nil {
+		return "", err
+	}
+	value, err = c.decode(value)
+	if errors.Is(err, errStaleTombstone) {
+		return "", ErrCacheNotFound
+	}
+	return value, err
+}
+
+func (c *NegativeCache) Set(key string, value string, expire time.Duration) error {
+	return c.Next.Set(key, c.encode(value), expire)
+}
+
+func (c *NegativeCache) Load(key string, loader func() (string, error), expire time.Duration) (string, error) {
+	value, err := c.load(key, loader, expire)
+	if !errors.Is(err, errStaleTombstone) {
+		return value, err
+	}
+	if err := c.Next.Delete(key); err != nil {
+		return "", err
+	}
+	value, err = c.load(key, loader, expire)
+	if !errors.Is(err, errStaleTombstone) {
+		return value, err
+	}
+	// replaced by a stale tombstone again in the meantime
+	return loader()
+}
+
+func (c *NegativeCache) load(key string, loader func() (string, error), expire time.Duration) (string, error) {
+	cached := -1
+	value, err := c.Next.Load(key, func() (string, error) {
+		value, err := loader()
+		if err != nil {
+			if index, ok := c.match(err); ok {
+				cached = index
+			}
+			return "", err
+		}
+		return c.encode(value), nil
+	}, expire)
+	if err != nil {
+		if cached >= 0 {
+			if err := c.Next.Set(key, c.tombstone(cached), c.ttl); err != nil {
+				return "", err
+			}
+		}
+		return "", err
+	}
+	return c.decode(value)
+}
+
+// Has reports whether a value exists, tombstones are not values.
+func (c *NegativeCache) Has(key string) bool {
+	_, err := c.Get(key)
+	return err == nil
+}
+
+// WithNegativeCache caches the configured loader errors of the cache as tombstones.
+func WithNegativeCache[T any](config *NegativeConfig) OptionFunc[T] {
+	return func(c *Cache[T]) error {
+		store, err := NewNegativeCache(c.Cache, config)
+		if err != nil {
+			return err
+		}
+		c.Cache = store
+		return nil
+	}
+}
@@ -0,0 +1,142 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errRecordNotFound = errors.New("record not found")
+
+// staleTombstoneStore always loads the tombstone of an error which is no longer configured.
+type staleTombstoneStore struct {
+	*mapStore
+	loads int
+}
+
+func (s *staleTombstoneStore) Load(string, func() (string, error), time.Duration) (string, error) {
+	s.loads++
+	return negativePrefix + "t\x09", nil
+}
+
+func TestNegativeCache(t *testing.T) {
+	t.Run("cache configured errors", func(t *testing.T) {
+		store := newMapStore()
+		c, err := NewNegativeCache(store, &NegativeConfig{Errors: []error{errRecordNotFound}, TTL: time.Minute})
+		if err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		calls := 0
+		loader := func() (string, error) {
+			calls++
+			return "", fmt.Errorf("find user: %w", errRecordNotFound)
+		}
+		_, err = c.Load("user:1", loader, time.Hour)
+		assert.ErrorIs(t, err, errRecordNotFound)
+		_, err = c.Load("user:1", loader, time.Hour)
+		assert.ErrorIs(t, err, errRecordNotFound)
+		assert.Equal(t, 1, calls)
+		_, err = c.Get("user:1")
+		assert.Same(t, errRecordNotFound, err)
+		assert.False(t, c.Has("user:1"))
+		assert.True(t, store.expire["user:1"].Before(time.Now().Add(2*time.Minute)))
+
+		assert.NoError(t, c.Delete("user:1"))
+		value, err := c.Load("user:1", func() (string, error) { return "gopi", nil }, time.Hour)
+		assert.NoError(t, err)
+		assert.Equal(t, "gopi", value)
+	})
+
+	t.Run("other errors are not cached", func(t *testing.T) {
+		store := newMapStore()
+		c, _ := NewNegativeCache(store, &NegativeConfig{Errors: []error{errRecordNotFound}})
+		_, err := c.Load("key", func() (string, error) { return "", errors.New("timeout") }, 0)
+		assert.EqualError(t, err, "timeout")
+		assert.False(t, store.Has("key"))
+	})
+
+	t.Run("values never collide with tombstones", func(t *testing.T) {
+		store := newMapStore()
+		c, _ := NewNegativeCache(store, &NegativeConfig{Errors: []error{errRecordNotFound}})
+		tombstone := negativePrefix + "t\x00"
+		for _, value := range []string{"", "\x15record not found", "\x16", tombstone, negativePrefix + "v", negativePrefix, "plain"} {
+			assert.NoError(t, c.Set("key", value, 0))
+			got, err := c.Get("key")
+			assert.NoError(t, err)
+			assert.Equal(t, value, got)
+			got, err = c.Load("key", func() (string, error) { return "", nil }, 0)
+			assert.NoError(t, err)
+			assert.Equal(t, value, got)
+			assert.True(t, c.Has("key"))
+		}
+		value, err := c.Load("loaded", func() (string, error) { return tombstone, nil }, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, tombstone, value)
+		value, err = c.Get("loaded")
+		assert.NoError(t, err)
+		assert.Equal(t, tombstone, value)
+	})
+
+	t.Run("tombstone of removed error", func(t *testing.T) {
+		store := newMapStore()
+		assert.NoError(t, store.Set("key", negativePrefix+"t\x05", 0))
+		c, _ := NewNegativeCache(store, &NegativeConfig{Errors: []error{errRecordNotFound}})
+		_, err := c.Get("key")
+		assert.ErrorIs(t, err, ErrCacheNotFound)
+		value, err := c.Load("key", func() (string, error) { return "value", nil }, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, "value", value)
+	})
+
+	t.Run("errors with the same message", func(t *testing.T) {
+		errUserNotFound := errors.New("not found")
+		errOrderNotFound := errors.New("not found")
+		c, _ := NewNegativeCache(newMapStore(), &NegativeConfig{Errors: []error{errUserNotFound, errOrderNotFound}})
+		_, err := c.Load("order", func() (string, error) { return "", errOrderNotFound }, 0)
+		assert.Same(t, errOrderNotFound, err)
+		_, err = c.Get("order")
+		assert.Same(t, errOrderNotFound, err)
+	})
+
+	t.Run("stale tombstones are retried once", func(t *testing.T) {
+		store := &staleTombstoneStore{mapStore: newMapStore()}
+		c, _ := NewNegativeCache(store, &NegativeConfig{Errors: []error{errRecordNotFound}})
+		calls := 0
+		value, err := c.Load("key", func() (string, error) {
+			calls++
+			return "value", nil
+		}, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, "value", value)
+		assert.Equal(t, 2, store.loads)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("typed cache", func(t *testing.T) {
+		c, err := New[userV1](newMapStore(), WithNegativeCache[userV1](&NegativeConfig{Errors: []error{errRecordNotFound}}))
+		if err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		calls := 0
+		for i := 0; i < 2; i++ {
+			_, err = c.Load("user", func() (userV1, error) {
+				calls++
+				return userV1{}, errRecordNotFound
+			}, 0)
+			assert.ErrorIs(t, err, errRecordNotFound)
+		}
+		assert.Equal(t, 1, calls)
+		_, err = c.Get("user")
+		assert.ErrorIs(t, err, errRecordNotFound)
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		_, err := NewNegativeCache(newMapStore(), &NegativeConfig{})
+		assert.Error(t, err)
+		_, err = NegativeMiddleware(&NegativeConfig{})
+		assert.Error(t, err)
+	})
+}
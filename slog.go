@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+func init() {
+	RegisterMiddleware("slog", func(store string, options map[string]any) (Middleware, error) {
+		var config struct {
+			// Threshold is the duration above which operations are logged as slow.
+			Threshold time.Duration `json:"threshold" yaml:"threshold" toml:"threshold" mapstructure:"threshold"`
+		}
+		if err := DecodeConfig(options, &config); err != nil {
+			return nil, err
+		}
+		return HookMiddleware(store, NewSlogHook(nil, config.Threshold)), nil
+	})
+}
+
+// SlogHook logs failed cache operations at error level, and operations slower than the threshold at warn level.
+// Misses are not logged as failures.
+type SlogHook struct {
+	logger    *slog.Logger
+	threshold time.Duration
+}
+
+// NewSlogHook creates a logging hook, the default logger is used if logger is nil.
+// Slow operations are not logged if threshold is not positive.
+func NewSlogHook(logger *slog.Logger, threshold time.Duration) *SlogHook {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &SlogHook{logger: logger, threshold: threshold}
+}
+
+func (h *SlogHook) BeforeOp(ctx context.Context, _ *Op) context.Context {
+	return ctx
+}
+
+func (h *SlogHook) AfterOp(ctx context.Context, op *Op) {
+	attrs := []slog.Attr{
+		slog.String("op", op.Name),
+		slog.String("store", op.Store),
+		slog.String("key", op.Key),
+		slog.Duration("duration", op.Duration),
+	}
+	switch {
+	case op.Err != nil && !errors.Is(op.Err, ErrCacheNotFound):
+		h.logger.LogAttrs(ctx, slog.LevelError, "cache operation failed", append(attrs, slog.Any("error", op.Err))...)
+	case h.threshold > 0 && op.Duration >= h.threshold:
+		h.logger.LogAttrs(ctx, slog.LevelWarn, "slow cache operation", attrs...)
+	}
+}
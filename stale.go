@@ -0,0 +1,209 @@
+package cache
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gopi-frame/contract/cache"
+)
+
+func init() {
+	RegisterMiddleware("stale", func(_ string, options map[string]any) (Middleware, error) {
+		config := new(StaleConfig)
+		if err := DecodeConfig(options, config); err != nil {
+			return nil, err
+		}
+		return StaleMiddleware(config), nil
+	})
+}
+
+// StaleConfig is the stale-while-revalidate config.
+type StaleConfig struct {
+	// TTL is the time a value is fresh when no expire time is given, default is 1 hour.
+	TTL time.Duration `json:"ttl" yaml:"ttl" toml:"ttl" mapstructure:"ttl"`
+	// StaleWhileRevalidate is the time after a value becomes stale during which Load returns it
+	// and refreshes it in the background, default is 1 minute.
+	StaleWhileRevalidate time.Duration `json:"stale_while_revalidate" yaml:"stale_while_revalidate" toml:"stale_while_revalidate" mapstructure:"stale_while_revalidate"`
+	// StaleIfError is the time after the revalidation window during which Load returns the stale value
+	// if the loader fails, it is disabled by default.
+	StaleIfError time.Duration `json:"stale_if_error" yaml:"stale_if_error" toml:"stale_if_error" mapstructure:"stale_if_error"`
+	// OnRefreshError is called with the errors of background refreshes.
+	OnRefreshError func(key string, err error) `json:"-" yaml:"-" toml:"-" mapstructure:"-"`
+}
+
+// ApplyDefaults sets the default values of unset fields.
+func (c *StaleConfig) ApplyDefaults() {
+	if c.TTL <= 0 {
+		c.TTL = time.Hour
+	}
+	if c.StaleWhileRevalidate <= 0 {
+		c.StaleWhileRevalidate = time.Minute
+	}
+	if c.StaleIfError < 0 {
+		c.StaleIfError = 0
+	}
+}
+
+// StaleCache serves stale values while they are refreshed.
+//
+// A value is fresh for its expire time, called the soft TTL. After that, Load returns the stale value
+// immediately and refreshes it in the background, until the hard TTL, which is the soft TTL plus the
+// revalidation window. Past the hard TTL, Load blocks on the loader, and returns the stale value if the loader
+// fails within the stale-if-error grace period. Loads of the same key are deduplicated.
+//
+// The soft and hard expiry are stored in an envelope along with the value rather than in driver specific
+// fields, so every driver, including those of other packages, stores them the same way without changes,
+// and values written without the envelope are read as fresh. The store keeps values for the hard TTL
+// plus the grace period.
+type StaleCache struct {
+	Forwarder
+	ttl            time.Duration
+	revalidate     time.Duration
+	staleIfError   time.Duration
+	onRefreshError func(key string, err error)
+	flights        *flights
+	now            func() time.Time
+	// refreshed is called when a background refresh is done.
+	refreshed func(key string)
+}
+
+// NewStaleCache creates a stale-while-revalidate cache on store.
+func NewStaleCache(store cache.Cache, config *StaleConfig) *StaleCache {
+	config.ApplyDefaults()
+	return &StaleCache{
+		Forwarder:      Forwarder{Next: store},
+		ttl:            config.TTL,
+		revalidate:     config.StaleWhileRevalidate,
+		staleIfError:   config.StaleIfError,
+		onRefreshError: config.OnRefreshError,
+		flights:        newFlights(),
+		now:            time.Now,
+	}
+}
+
+// StaleMiddleware returns a middleware which wraps stores with [NewStaleCache].
+func StaleMiddleware(config *StaleConfig) Middleware {
+	return func(next cache.Cache) cache.Cache {
+		return NewStaleCache(next, config)
+	}
+}
+
+// entry reads the entry of key, values written without an envelope are fresh.
+func (c *StaleCache) entry(key string) (entry, error) {
+	value, err := c.Next.Get(key)
+	if err != nil {
+		return entry{}, err
+	}
+	e, ok := decodeEntry(value)
+	if !ok {
+		now := c.now()
+		return entry{value: value, soft: now.Add(c.ttl), hard: now.Add(c.ttl + c.revalidate)}, nil
+	}
+	return e, nil
+}
+
+func (c *StaleCache) set(key string, value string, expire time.Duration, delta time.Duration) error {
+	if expire <= 0 {
+		expire = c.ttl
+	}
+	now := c.now()
+	e := entry{
+		value: value,
+		soft:  now.Add(expire),
+		hard:  now.Add(expire + c.revalidate),
+		delta: delta,
+	}
+	return c.Next.Set(key, e.encode(), expire+c.revalidate+c.staleIfError)
+}
+
+// load calls the loader and stores its value, concurrent loads of the same key share one call.
+func (c *StaleCache) load(key string, loader func() (string, error), expire time.Duration) (string, error) {
+	return c.flights.do(key, c.loadFunc(key, loader, expire))
+}
+
+func (c *StaleCache) loadFunc(key string, loader func() (string, error), expire time.Duration) func() (string, error) {
+	return func() (string, error) {
+		start := c.now()
+		value, err := loader()
+		if err != nil {
+			return "", err
+		}
+		if err := c.set(key, value, expire, c.now().Sub(start)); err != nil {
+			return "", err
+		}
+		return value, nil
+	}
+}
+
+// refresh reloads the value of key in the background, unless it is already being loaded.
+func (c *StaleCache) refresh(key string, loader func() (string, error), expire time.Duration) {
+	call, owner := c.flights.start(key)
+	if !owner {
+		return
+	}
+	go func() {
+		defer func() {
+			c.flights.finish(key, call)
+			if c.refreshed != nil {
+				c.refreshed(key)
+			}
+		}()
+		call.value, call.err = c.loadFunc(key, loader, expire)()
+		if call.err != nil && c.onRefreshError != nil {
+			c.onRefreshError(key, call.err)
+		}
+	}()
+}
+
+// Get returns the value until its hard TTL, whether it is stale or not.
+func (c *StaleCache) Get(key string) (string, error) {
+	e, err := c.entry(key)
+	if err != nil {
+		return "", err
+	}
+	if !c.now().Before(e.hard) {
+		return "", ErrCacheNotFound
+	}
+	return e.value, nil
+}
+
+func (c *StaleCache) Set(key string, value string, expire time.Duration) error {
+	return c.set(key, value, expire, 0)
+}
+
+func (c *StaleCache) Load(key string, loader func() (string, error), expire time.Duration) (string, error) {
+	e, err := c.entry(key)
+	if err != nil {
+		if errors.Is(err, ErrCacheNotFound) {
+			return c.load(key, loader, expire)
+		}
+		return "", err
+	}
+	now := c.now()
+	switch {
+	case now.Before(e.soft):
+		return e.value, nil
+	case now.Before(e.hard):
+		c.refresh(key, loader, expire)
+		return e.value, nil
+	}
+	value, err := c.load(key, loader, expire)
+	if err != nil && now.Before(e.hard.Add(c.staleIfError)) {
+		return e.value, nil
+	}
+	return value, err
+}
+
+// Has reports whether a value can be served, whether it is stale or not.
+func (c *StaleCache) Has(key string) bool {
+	_, err := c.Get(key)
+	return err == nil
+}
+
+// WithStaleWhileRevalidate serves stale values of the cache while they are refreshed, see [StaleCache].
+func WithStaleWhileRevalidate[T any](config *StaleConfig) OptionFunc[T] {
+	return func(c *Cache[T]) error {
+		c.Cache = NewStaleCache(c.Cache, config)
+		return nil
+	}
+}
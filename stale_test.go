@@ -0,0 +1,259 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testClock is a clock which only moves when advanced.
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newTestClock() *testClock {
+	return &testClock{now: time.UnixMilli(time.Now().UnixMilli())}
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestStaleCache(t *testing.T) {
+	config := func() *StaleConfig {
+		return &StaleConfig{StaleWhileRevalidate: time.Minute, StaleIfError: time.Minute}
+	}
+	newCache := func(config *StaleConfig) (*StaleCache, *testClock, chan string) {
+		clock := newTestClock()
+		refreshed := make(chan string, 10)
+		c := NewStaleCache(newMapStore(), config)
+		c.now = clock.Now
+		c.refreshed = func(key string) {
+			refreshed <- key
+		}
+		return c, clock, refreshed
+	}
+
+	t.Run("fresh", func(t *testing.T) {
+		c, _, _ := newCache(config())
+		var calls atomic.Int32
+		loader := func() (string, error) {
+			calls.Add(1)
+			return "gopi", nil
+		}
+		for i := 0; i < 3; i++ {
+			value, err := c.Load("key", loader, time.Minute)
+			assert.NoError(t, err)
+			assert.Equal(t, "gopi", value)
+		}
+		assert.Equal(t, int32(1), calls.Load())
+		value, err := c.Get("key")
+		assert.NoError(t, err)
+		assert.Equal(t, "gopi", value)
+		assert.True(t, c.Has("key"))
+	})
+
+	t.Run("stale while revalidate", func(t *testing.T) {
+		c, clock, refreshed := newCache(config())
+		assert.NoError(t, c.Set("key", "old", time.Minute))
+		clock.Advance(time.Minute + time.Second)
+		value, err := c.Load("key", func() (string, error) { return "new", nil }, time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, "old", value)
+		assert.Equal(t, "key", <-refreshed)
+		value, err = c.Get("key")
+		assert.NoError(t, err)
+		assert.Equal(t, "new", value)
+	})
+
+	t.Run("windows", func(t *testing.T) {
+		c, clock, refreshed := newCache(&StaleConfig{StaleWhileRevalidate: time.Minute})
+		var calls atomic.Int32
+		loader := func() (string, error) {
+			return fmt.Sprintf("v%d", calls.Add(1)), nil
+		}
+		load := func() string {
+			value, err := c.Load("key", loader, time.Minute)
+			assert.NoError(t, err)
+			return value
+		}
+
+		// fresh: served from the store without loading
+		assert.Equal(t, "v1", load())
+		clock.Advance(59 * time.Second)
+		assert.Equal(t, "v1", load())
+		assert.Equal(t, int32(1), calls.Load())
+		assert.Empty(t, refreshed)
+
+		// stale: served while refreshed in the background
+		clock.Advance(30 * time.Second)
+		assert.Equal(t, "v1", load())
+		assert.Equal(t, "key", <-refreshed)
+		assert.Equal(t, int32(2), calls.Load())
+		// the refreshed value is fresh for another minute
+		clock.Advance(59 * time.Second)
+		assert.Equal(t, "v2", load())
+		assert.Equal(t, int32(2), calls.Load())
+
+		// expired: past the stale window the value is loaded in the foreground
+		clock.Advance(2 * time.Minute)
+		_, err := c.Get("key")
+		assert.ErrorIs(t, err, ErrCacheNotFound)
+		assert.False(t, c.Has("key"))
+		assert.Equal(t, "v3", load())
+		assert.Equal(t, int32(3), calls.Load())
+		assert.Empty(t, refreshed)
+	})
+
+	t.Run("refreshes are deduplicated", func(t *testing.T) {
+		c, clock, refreshed := newCache(config())
+		assert.NoError(t, c.Set("key", "old", time.Minute))
+		clock.Advance(time.Minute + time.Second)
+		var calls atomic.Int32
+		release := make(chan struct{})
+		loader := func() (string, error) {
+			calls.Add(1)
+			<-release
+			return "new", nil
+		}
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				value, err := c.Load("key", loader, time.Minute)
+				assert.NoError(t, err)
+				assert.Equal(t, "old", value)
+			}()
+		}
+		wg.Wait()
+		close(release)
+		assert.Equal(t, "key", <-refreshed)
+		value, err := c.Get("key")
+		assert.NoError(t, err)
+		assert.Equal(t, "new", value)
+		assert.Equal(t, int32(1), calls.Load())
+		assert.Empty(t, refreshed)
+	})
+
+	t.Run("blocks past hard ttl", func(t *testing.T) {
+		c, clock, _ := newCache(&StaleConfig{StaleWhileRevalidate: time.Minute})
+		assert.NoError(t, c.Set("key", "old", time.Minute))
+		clock.Advance(2 * time.Minute)
+		_, err := c.Get("key")
+		assert.ErrorIs(t, err, ErrCacheNotFound)
+		value, err := c.Load("key", func() (string, error) { return "new", nil }, time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, "new", value)
+	})
+
+	t.Run("stale if error", func(t *testing.T) {
+		c, clock, _ := newCache(&StaleConfig{StaleWhileRevalidate: time.Minute, StaleIfError: time.Hour})
+		assert.NoError(t, c.Set("key", "old", time.Minute))
+		clock.Advance(2 * time.Minute)
+		failing := func() (string, error) { return "", errors.New("timeout") }
+		value, err := c.Load("key", failing, time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, "old", value)
+
+		clock.Advance(time.Hour)
+		_, err = c.Load("key", failing, time.Minute)
+		assert.EqualError(t, err, "timeout")
+	})
+
+	t.Run("refresh errors", func(t *testing.T) {
+		var errs []error
+		c, clock, refreshed := newCache(&StaleConfig{
+			StaleWhileRevalidate: time.Minute,
+			OnRefreshError: func(key string, err error) {
+				errs = append(errs, err)
+			},
+		})
+		assert.NoError(t, c.Set("key", "old", time.Minute))
+		clock.Advance(time.Minute + time.Second)
+		value, err := c.Load("key", func() (string, error) { return "", errors.New("timeout") }, time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, "old", value)
+		assert.Equal(t, "key", <-refreshed)
+		if assert.Len(t, errs, 1) {
+			assert.EqualError(t, errs[0], "timeout")
+		}
+	})
+
+	t.Run("records delta", func(t *testing.T) {
+		store := newMapStore()
+		clock := newTestClock()
+		c := NewStaleCache(store, config())
+		c.now = clock.Now
+		_, err := c.Load("key", func() (string, error) {
+			clock.Advance(250 * time.Millisecond)
+			return "value", nil
+		}, time.Minute)
+		assert.NoError(t, err)
+		raw, _ := store.Get("key")
+		e, ok := decodeEntry(raw)
+		assert.True(t, ok)
+		assert.Equal(t, 250*time.Millisecond, e.delta)
+		assert.Equal(t, clock.Now().Add(time.Minute), e.soft)
+		assert.Equal(t, clock.Now().Add(2*time.Minute), e.hard)
+	})
+
+	t.Run("store expire", func(t *testing.T) {
+		store := newMapStore()
+		c := NewStaleCache(store, &StaleConfig{StaleWhileRevalidate: time.Minute, StaleIfError: time.Hour})
+		assert.NoError(t, c.Set("key", "value", time.Hour))
+		assert.WithinDuration(t, time.Now().Add(2*time.Hour+time.Minute), store.expire["key"], time.Second)
+	})
+
+	t.Run("values without envelope", func(t *testing.T) {
+		store := newMapStore()
+		assert.NoError(t, store.Set("key", "legacy", 0))
+		c := NewStaleCache(store, config())
+		value, err := c.Load("key", func() (string, error) { return "new", nil }, time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, "legacy", value)
+	})
+
+	t.Run("typed cache", func(t *testing.T) {
+		c, err := New[int](newMapStore(), WithStaleWhileRevalidate[int](config()))
+		if err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		value, err := c.Load("key", func() (int, error) { return 42, nil }, time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, 42, value)
+		value, err = c.Get("key")
+		assert.NoError(t, err)
+		assert.Equal(t, 42, value)
+	})
+}
+
+func TestEntry(t *testing.T) {
+	now := time.UnixMilli(time.Now().UnixMilli())
+	e := entry{value: entryPrefix + "value", soft: now, hard: now.Add(time.Minute), delta: 250 * time.Millisecond}
+	decoded, ok := decodeEntry(e.encode())
+	assert.True(t, ok)
+	assert.Equal(t, e.value, decoded.value)
+	assert.True(t, e.soft.Equal(decoded.soft))
+	assert.True(t, e.hard.Equal(decoded.hard))
+	assert.Equal(t, e.delta, decoded.delta)
+
+	for _, legacy := range []string{"value", "\x1f\x8b\x08\x00", "\x1f\xff", entryPrefix + "\xff"} {
+		_, ok = decodeEntry(legacy)
+		assert.False(t, ok)
+	}
+}
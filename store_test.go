@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gopi-frame/contract/cache"
+)
+
+// mapStore is a minimal in-memory store used by the tests of this package.
+type mapStore struct {
+	mu     sync.Mutex
+	data   map[string]string
+	expire map[string]time.Time
+	closed bool
+}
+
+func newMapStore() *mapStore {
+	return &mapStore{
+		data:   make(map[string]string),
+		expire: make(map[string]time.Time),
+	}
+}
+
+func (s *mapStore) Get(key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.data[key]
+	if !ok || (!s.expire[key].IsZero() && s.expire[key].Before(time.Now())) {
+		return "", ErrCacheNotFound
+	}
+	return v, nil
+}
+
+func (s *mapStore) Set(key string, value string, expire time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[key] = value
+	if expire > 0 {
+		s.expire[key] = time.Now().Add(expire)
+	} else {
+		delete(s.expire, key)
+	}
+	return nil
+}
+
+func (s *mapStore) Load(key string, loader func() (string, error), expire time.Duration) (string, error) {
+	if v, err := s.Get(key); err == nil {
+		return v, nil
+	}
+	v, err := loader()
+	if err != nil {
+		return "", err
+	}
+	return v, s.Set(key, v, expire)
+}
+
+func (s *mapStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.data, key)
+	delete(s.expire, key)
+	return nil
+}
+
+func (s *mapStore) Has(key string) bool {
+	_, err := s.Get(key)
+	return err == nil
+}
+
+func (s *mapStore) Clear() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data = make(map[string]string)
+	s.expire = make(map[string]time.Time)
+	return nil
+}
+
+func (s *mapStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+type mapDriver struct{}
+
+func (mapDriver) Open(config map[string]any) (cache.Cache, error) {
+	if fail, _ := config["fail"].(bool); fail {
+		return nil, ErrCacheNotFound
+	}
+	// "opens" counts the attempts to open.
+	if opens, ok := config["opens"].(*atomic.Int32); ok {
+		opens.Add(1)
+	}
+	// "block" delays opening until it is closed.
+	if block, ok := config["block"].(chan struct{}); ok {
+		<-block
+	}
+	// "failures" counts down the attempts which fail before the store opens.
+	if failures, ok := config["failures"].(*int); ok && *failures > 0 {
+		*failures--
+		return nil, ErrCacheNotFound
+	}
+	return newMapStore(), nil
+}
+
+func init() {
+	Register("test-map", mapDriver{})
+}
@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"context"
+	"errors"
+)
+
+// Tracer starts spans, it is the subset of an OpenTelemetry tracer used by [TracingHook].
+// An OpenTelemetry trace.Tracer can be adapted with a few lines, such as
+//
+//	type otelTracer struct{ trace.Tracer }
+//
+//	func (t otelTracer) Start(ctx context.Context, name string) (context.Context, cache.Span) {
+//		ctx, span := t.Tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient))
+//		return ctx, otelSpan{span}
+//	}
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is the subset of an OpenTelemetry span used by [TracingHook].
+type Span interface {
+	// SetAttribute sets a span attribute, value is a string, bool or int64.
+	SetAttribute(key string, value any)
+	// RecordError records err and marks the span as failed.
+	RecordError(err error)
+	// End completes the span.
+	End()
+}
+
+type spanContextKey struct{}
+
+// TracingHook emits a span named "cache.<op>" for every cache operation.
+// Misses are not recorded as errors.
+type TracingHook struct {
+	tracer     Tracer
+	recordKeys bool
+}
+
+// NewTracingHook creates a tracing hook.
+// Keys are recorded as the "cache.key" attribute only if recordKeys is true, as they may hold sensitive data.
+func NewTracingHook(tracer Tracer, recordKeys bool) *TracingHook {
+	return &TracingHook{tracer: tracer, recordKeys: recordKeys}
+}
+
+func (h *TracingHook) BeforeOp(ctx context.Context, op *Op) context.Context {
+	ctx, span := h.tracer.Start(ctx, "cache."+op.Name)
+	span.SetAttribute("cache.operation", op.Name)
+	span.SetAttribute("cache.store", op.Store)
+	if h.recordKeys && op.Key != "" {
+		span.SetAttribute("cache.key", op.Key)
+	}
+	return context.WithValue(ctx, spanContextKey{}, span)
+}
+
+func (h *TracingHook) AfterOp(ctx context.Context, op *Op) {
+	span, ok := ctx.Value(spanContextKey{}).(Span)
+	if !ok {
+		return
+	}
+	switch op.Name {
+	case OpGet, OpLoad, OpHas:
+		span.SetAttribute("cache.hit", op.Hit)
+	}
+	if op.Err != nil && !errors.Is(op.Err, ErrCacheNotFound) {
+		span.RecordError(op.Err)
+	}
+	span.End()
+}
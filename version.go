@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+
+	"github.com/gopi-frame/exception"
+)
+
+// versionPrefix starts the values stamped by [WithVersion], it is followed by the uvarint encoded version.
+var versionPrefix = []byte(envelopePrefix(envelopeVersion))
+
+// errVersionMismatch is returned by the decoding of a value of another version which cannot be upgraded.
+var errVersionMismatch = errors.New("cache value version mismatch")
+
+// WithVersion stamps every value with version, which has to be increased whenever T changes incompatibly.
+// Values of another version are treated as misses, so Get returns [ErrCacheNotFound] and Load reloads them,
+// unless an upgrade is registered for their version with [WithUpgrade].
+// Values written before versioning was enabled are of version 0.
+func WithVersion[T any](version uint64) OptionFunc[T] {
+	return func(c *Cache[T]) error {
+		if version == 0 {
+			return exception.NewArgumentException("version", version, "version must be greater than 0")
+		}
+		c.version = version
+		return nil
+	}
+}
+
+// WithUpgrade registers upgrade to convert values of version from, it receives the value as encoded by that version.
+// Upgraded values are returned as they are, they are rewritten with the current version on the next write.
+func WithUpgrade[T any](from uint64, upgrade func(data []byte) (T, error)) OptionFunc[T] {
+	return func(c *Cache[T]) error {
+		if upgrade == nil {
+			return exception.NewEmptyArgumentException("upgrade")
+		}
+		if c.upgrades == nil {
+			c.upgrades = make(map[uint64]func(data []byte) (T, error))
+		}
+		c.upgrades[from] = upgrade
+		return nil
+	}
+}
+
+func (c *Cache[T]) encode(value T) ([]byte, error) {
+	bs, err := c.encoder(value)
+	if err != nil || c.version == 0 {
+		return bs, err
+	}
+	stamp := binary.AppendUvarint(append([]byte(nil), versionPrefix...), c.version)
+	return append(stamp, bs...), nil
+}
+
+func (c *Cache[T]) decode(bs []byte) (T, error) {
+	if c.version == 0 {
+		return c.decoder(bs)
+	}
+	var version uint64
+	if payload, ok := bytes.CutPrefix(bs, versionPrefix); ok {
+		v, n := binary.Uvarint(payload)
+		if n <= 0 {
+			return *new(T), errVersionMismatch
+		}
+		version = v
+		bs = payload[n:]
+	}
+	if version == c.version {
+		return c.decoder(bs)
+	}
+	if upgrade, ok := c.upgrades[version]; ok {
+		return upgrade(bs)
+	}
+	return *new(T), errVersionMismatch
+}
@@ -0,0 +1,179 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type userV1 struct {
+	Name string `json:"name"`
+}
+
+type userV2 struct {
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+}
+
+func TestCache_Version(t *testing.T) {
+	t.Run("mismatch is a miss", func(t *testing.T) {
+		store := newMapStore()
+		v1, _ := New[userV1](store, WithVersion[userV1](1))
+		v2, _ := New[userV2](store, WithVersion[userV2](2))
+		assert.NoError(t, v1.Set("user", userV1{Name: "Gopi Frame"}, 0))
+		_, err := v2.Get("user")
+		assert.ErrorIs(t, err, ErrCacheNotFound)
+		assert.False(t, v2.Has("user"))
+		assert.True(t, v1.Has("user"))
+
+		value, err := v2.Load("user", func() (userV2, error) {
+			return userV2{FirstName: "Gopi", LastName: "Frame"}, nil
+		}, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, "Gopi", value.FirstName)
+		value, err = v2.Get("user")
+		assert.NoError(t, err)
+		assert.Equal(t, "Frame", value.LastName)
+		_, err = v1.Get("user")
+		assert.ErrorIs(t, err, ErrCacheNotFound)
+	})
+
+	t.Run("mismatch is reloaded through the store", func(t *testing.T) {
+		store := &loadCountingStore{mapStore: newMapStore()}
+		v1, _ := New[userV1](store, WithVersion[userV1](1))
+		v2, _ := New[userV2](store, WithVersion[userV2](2))
+		assert.NoError(t, v1.Set("user", userV1{Name: "Gopi Frame"}, 0))
+		store.sets = 0
+		value, err := v2.Load("user", func() (userV2, error) {
+			return userV2{FirstName: "Gopi"}, nil
+		}, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, "Gopi", value.FirstName)
+		assert.Equal(t, 2, store.loads)
+		assert.Equal(t, 0, store.sets)
+	})
+
+	t.Run("upgrade", func(t *testing.T) {
+		store := newMapStore()
+		upgrade := WithUpgrade[userV2](1, func(data []byte) (userV2, error) {
+			var old userV1
+			if err := json.Unmarshal(data, &old); err != nil {
+				return userV2{}, err
+			}
+			return userV2{FirstName: old.Name}, nil
+		})
+		v1, _ := New[userV1](store, WithVersion[userV1](1))
+		v2, _ := New[userV2](store, WithVersion[userV2](2), upgrade)
+		assert.NoError(t, v1.Set("user", userV1{Name: "Gopi"}, 0))
+		value, err := v2.Get("user")
+		assert.NoError(t, err)
+		assert.Equal(t, userV2{FirstName: "Gopi"}, value)
+		value, err = v2.Load("user", func() (userV2, error) {
+			assert.Fail(t, "upgraded value should not be reloaded")
+			return userV2{}, nil
+		}, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, "Gopi", value.FirstName)
+	})
+
+	t.Run("unversioned values are version 0", func(t *testing.T) {
+		store := newMapStore()
+		unversioned, _ := New[userV1](store)
+		assert.NoError(t, unversioned.Set("user", userV1{Name: "Gopi"}, 0))
+		v1, _ := New[userV1](store, WithVersion[userV1](1))
+		_, err := v1.Get("user")
+		assert.ErrorIs(t, err, ErrCacheNotFound)
+		v1, _ = New[userV1](store, WithVersion[userV1](1), WithUpgrade[userV1](0, func(data []byte) (userV1, error) {
+			var value userV1
+			return value, json.Unmarshal(data, &value)
+		}))
+		value, err := v1.Get("user")
+		assert.NoError(t, err)
+		assert.Equal(t, "Gopi", value.Name)
+	})
+
+	t.Run("unversioned values starting like the old marker", func(t *testing.T) {
+		// gob prefixes messages of 256 bytes or more with 0xFE
+		var buf bytes.Buffer
+		assert.NoError(t, gob.NewEncoder(&buf).Encode(strings.Repeat("a", 300)))
+		assert.Equal(t, byte(0xFE), buf.Bytes()[0])
+		store := newMapStore()
+		assert.NoError(t, store.Set("legacy", buf.String(), 0))
+		c, _ := New[string](store, WithVersion[string](1), WithUpgrade[string](0, func(data []byte) (string, error) {
+			var value string
+			return value, gob.NewDecoder(bytes.NewReader(data)).Decode(&value)
+		}))
+		value, err := c.Get("legacy")
+		assert.NoError(t, err)
+		assert.Equal(t, strings.Repeat("a", 300), value)
+	})
+
+	t.Run("with codec", func(t *testing.T) {
+		c, _ := New[userV1](newMapStore(), WithCodec[userV1](GobCodec[userV1]()), WithVersion[userV1](300))
+		assert.NoError(t, c.Set("user", userV1{Name: "Gopi"}, 0))
+		value, err := c.Get("user")
+		assert.NoError(t, err)
+		assert.Equal(t, "Gopi", value.Name)
+	})
+
+	t.Run("invalid version", func(t *testing.T) {
+		_, err := New[userV1](newMapStore(), WithVersion[userV1](0))
+		assert.Error(t, err)
+	})
+}
+
+type loadCountingStore struct {
+	*mapStore
+	loads int
+	sets  int
+}
+
+func (s *loadCountingStore) Set(key string, value string, expire time.Duration) error {
+	s.sets++
+	return s.mapStore.Set(key, value, expire)
+}
+
+func (s *loadCountingStore) Load(key string, loader func() (string, error), expire time.Duration) (string, error) {
+	s.loads++
+	return s.mapStore.Load(key, loader, expire)
+}
+
+func TestCache_Load(t *testing.T) {
+	type session struct {
+		User  string `json:"user"`
+		token string
+	}
+	c, _ := New[session](newMapStore())
+	value, err := c.Load("session", func() (session, error) {
+		return session{User: "gopi", token: "secret"}, nil
+	}, 0)
+	assert.NoError(t, err)
+	// the loaded value is returned as it is, not decoded from the store
+	assert.Equal(t, "secret", value.token)
+	value, err = c.Load("session", func() (session, error) {
+		return session{}, nil
+	}, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, session{User: "gopi"}, value)
+}
+
+func TestCache_LoadConcurrently(t *testing.T) {
+	c, _ := New[int](newMapStore())
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			value, err := c.Load("key", func() (int, error) { return i, nil }, 0)
+			assert.NoError(t, err)
+			assert.GreaterOrEqual(t, value, 0)
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,149 @@
+package cache
+
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"github.com/gopi-frame/contract/cache"
+)
+
+func init() {
+	RegisterMiddleware("xfetch", func(_ string, options map[string]any) (Middleware, error) {
+		config := new(XFetchConfig)
+		if err := DecodeConfig(options, config); err != nil {
+			return nil, err
+		}
+		return XFetchMiddleware(config), nil
+	})
+}
+
+// XFetchConfig is the probabilistic early expiration config.
+type XFetchConfig struct {
+	// TTL is the expire time of values when no expire time is given, default is 1 hour.
+	TTL time.Duration `json:"ttl" yaml:"ttl" toml:"ttl" mapstructure:"ttl"`
+	// Beta scales how early values are refreshed, values above 1 favor earlier refreshes, default is 1.
+	Beta float64 `json:"beta" yaml:"beta" toml:"beta" mapstructure:"beta"`
+}
+
+// ApplyDefaults sets the default values of unset fields.
+func (c *XFetchConfig) ApplyDefaults() {
+	if c.TTL <= 0 {
+		c.TTL = time.Hour
+	}
+	if c.Beta <= 0 {
+		c.Beta = 1
+	}
+}
+
+// XFetchCache refreshes values before they expire with a probability which grows as the expiry approaches,
+// so instances sharing a store rarely reload the same key at the same time.
+//
+// Load records how long the loader takes, called the delta, along with the value, and reloads the value early
+// when now - delta * beta * ln(rand()) reaches its expiry, as in the XFetch algorithm.
+// Values are served until the early reload succeeds, and concurrent reloads of the same key are deduplicated.
+type XFetchCache struct {
+	Forwarder
+	ttl     time.Duration
+	beta    float64
+	rand    func() float64
+	now     func() time.Time
+	flights *flights
+}
+
+// NewXFetchCache creates a probabilistic early expiration cache on store.
+func NewXFetchCache(store cache.Cache, config *XFetchConfig) *XFetchCache {
+	config.ApplyDefaults()
+	return &XFetchCache{
+		Forwarder: Forwarder{Next: store},
+		ttl:       config.TTL,
+		beta:      config.Beta,
+		rand:      rand.Float64,
+		now:       time.Now,
+		flights:   newFlights(),
+	}
+}
+
+// XFetchMiddleware returns a middleware which wraps stores with [NewXFetchCache].
+func XFetchMiddleware(config *XFetchConfig) Middleware {
+	return func(next cache.Cache) cache.Cache {
+		return NewXFetchCache(next, config)
+	}
+}
+
+func (c *XFetchCache) set(key string, value string, expire time.Duration, delta time.Duration) error {
+	if expire <= 0 {
+		expire = c.ttl
+	}
+	expiry := c.now().Add(expire)
+	e := entry{value: value, soft: expiry, hard: expiry, delta: delta}
+	return c.Next.Set(key, e.encode(), expire)
+}
+
+func (c *XFetchCache) load(key string, loader func() (string, error), expire time.Duration) (string, error) {
+	return c.flights.do(key, func() (string, error) {
+		start := c.now()
+		value, err := loader()
+		if err != nil {
+			return "", err
+		}
+		if err := c.set(key, value, expire, c.now().Sub(start)); err != nil {
+			return "", err
+		}
+		return value, nil
+	})
+}
+
+// early reports whether e should be reloaded before it expires.
+func (c *XFetchCache) early(e entry) bool {
+	// 1 - rand() is in (0, 1], so the logarithm is finite
+	gap := time.Duration(float64(e.delta) * c.beta * -math.Log(1-c.rand()))
+	return !c.now().Add(gap).Before(e.hard)
+}
+
+func (c *XFetchCache) Get(key string) (string, error) {
+	value, err := c.Next.Get(key)
+	if err != nil {
+		return "", err
+	}
+	if e, ok := decodeEntry(value); ok {
+		return e.value, nil
+	}
+	return value, nil
+}
+
+func (c *XFetchCache) Set(key string, value string, expire time.Duration) error {
+	return c.set(key, value, expire, 0)
+}
+
+func (c *XFetchCache) Load(key string, loader func() (string, error), expire time.Duration) (string, error) {
+	value, err := c.Next.Get(key)
+	if err != nil {
+		if errors.Is(err, ErrCacheNotFound) {
+			return c.load(key, loader, expire)
+		}
+		return "", err
+	}
+	e, ok := decodeEntry(value)
+	if !ok {
+		return value, nil
+	}
+	if !c.early(e) {
+		return e.value, nil
+	}
+	// the value is still served if the early reload fails
+	value, err = c.load(key, loader, expire)
+	if err != nil && c.now().Before(e.hard) {
+		return e.value, nil
+	}
+	return value, err
+}
+
+// WithXFetch refreshes the values of the cache before they expire, see [XFetchCache].
+func WithXFetch[T any](config *XFetchConfig) OptionFunc[T] {
+	return func(c *Cache[T]) error {
+		c.Cache = NewXFetchCache(c.Cache, config)
+		return nil
+	}
+}
@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestXFetchCache(t *testing.T) {
+	newCache := func(store *mapStore, config *XFetchConfig) (*XFetchCache, *testClock) {
+		clock := newTestClock()
+		c := NewXFetchCache(store, config)
+		c.now = clock.Now
+		return c, clock
+	}
+	// slowLoader takes 20ms on the clock
+	slowLoader := func(clock *testClock, value string, calls *int) func() (string, error) {
+		return func() (string, error) {
+			*calls++
+			clock.Advance(20 * time.Millisecond)
+			return value, nil
+		}
+	}
+
+	t.Run("records delta", func(t *testing.T) {
+		store := newMapStore()
+		c, clock := newCache(store, &XFetchConfig{})
+		calls := 0
+		value, err := c.Load("key", slowLoader(clock, "gopi", &calls), time.Hour)
+		assert.NoError(t, err)
+		assert.Equal(t, "gopi", value)
+		raw, _ := store.Get("key")
+		e, ok := decodeEntry(raw)
+		assert.True(t, ok)
+		assert.Equal(t, 20*time.Millisecond, e.delta)
+		assert.Equal(t, clock.Now().Add(time.Hour), e.hard)
+
+		value, err = c.Get("key")
+		assert.NoError(t, err)
+		assert.Equal(t, "gopi", value)
+	})
+
+	t.Run("far from expiry", func(t *testing.T) {
+		c, clock := newCache(newMapStore(), &XFetchConfig{})
+		c.rand = func() float64 { return 0.99 }
+		calls := 0
+		for i := 0; i < 3; i++ {
+			_, err := c.Load("key", slowLoader(clock, "gopi", &calls), time.Hour)
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("early refresh", func(t *testing.T) {
+		c, clock := newCache(newMapStore(), &XFetchConfig{Beta: 10})
+		calls := 0
+		_, err := c.Load("key", slowLoader(clock, "old", &calls), time.Minute)
+		assert.NoError(t, err)
+		clock.Advance(time.Minute - 100*time.Millisecond)
+
+		// -ln(1-0.5) * 10 * 20ms is about 140ms, past the expiry
+		c.rand = func() float64 { return 0.5 }
+		value, err := c.Load("key", slowLoader(clock, "new", &calls), time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, "new", value)
+		assert.Equal(t, 2, calls)
+
+		// -ln(1-0) is 0, so values are only refreshed once expired
+		clock.Advance(time.Minute - 100*time.Millisecond)
+		c.rand = func() float64 { return 0 }
+		value, err = c.Load("key", slowLoader(clock, "newer", &calls), time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, "new", value)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("failed early refresh", func(t *testing.T) {
+		c, clock := newCache(newMapStore(), &XFetchConfig{Beta: 10})
+		calls := 0
+		_, err := c.Load("key", slowLoader(clock, "old", &calls), time.Minute)
+		assert.NoError(t, err)
+		clock.Advance(time.Minute - 100*time.Millisecond)
+		c.rand = func() float64 { return 0.5 }
+		value, err := c.Load("key", func() (string, error) { return "", errors.New("timeout") }, time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, "old", value)
+
+		clock.Advance(time.Second)
+		_, err = c.Load("key", func() (string, error) { return "", errors.New("timeout") }, time.Minute)
+		assert.EqualError(t, err, "timeout")
+	})
+
+	t.Run("values without envelope", func(t *testing.T) {
+		store := newMapStore()
+		assert.NoError(t, store.Set("key", "legacy", 0))
+		c := NewXFetchCache(store, &XFetchConfig{})
+		value, err := c.Load("key", func() (string, error) { return "new", nil }, time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, "legacy", value)
+	})
+
+	t.Run("middleware", func(t *testing.T) {
+		mw, err := OpenMiddleware("xfetch", "default", map[string]any{"beta": 2.0})
+		if err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		assert.Equal(t, 2.0, mw(newMapStore()).(*XFetchCache).beta)
+	})
+}